@@ -0,0 +1,71 @@
+// 'append' command adds text to an existing note without an editor
+package main
+
+import (
+	"io"
+	"strings"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/spf13/cobra"
+)
+
+var appendCmd = &cobra.Command{
+	Use:   "append [title] [text...]",
+	Short: "Append text to a note",
+	Long: `Append text to the end of a note without opening the editor.
+
+The text is read from the arguments, or from stdin if no text is provided or
+the text is '-'. Entries can be added as bullets, prefixed with the current
+time, and placed at the end of the section under a heading, which is created
+if it doesn't exist.`,
+	Example: `  note append journal "Deployed the new release"
+  note append standup --heading Done --bullet "Reviewed the sync changes"
+  git log -1 --format=%s | note append changelog --bullet --timestamp`,
+	ValidArgsFunction: completeNotes,
+	Args:              cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Validate title input
+		title := args[0]
+		if title == "" {
+			cmd.PrintErr("title cannot be empty")
+			return
+		}
+
+		// Read the text from the arguments or stdin
+		text := strings.Join(args[1:], " ")
+		if len(args) == 1 || text == "-" {
+			content, err := io.ReadAll(cmd.InOrStdin())
+			errHandler(cmd, err)
+			text = string(content)
+		}
+		if strings.TrimSpace(text) == "" {
+			cmd.PrintErr("text cannot be empty")
+			return
+		}
+
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+		// Only append to loosely matched notes once the user confirms them
+		title = resolveExactTitle(cmd, manager, title)
+		usePassphrase(manager, false)
+
+		// Append the text
+		opts := note.AppendOptions{}
+		opts.Heading, _ = cmd.Flags().GetString("heading")
+		opts.Bullet, _ = cmd.Flags().GetBool("bullet")
+		opts.Timestamp, _ = cmd.Flags().GetBool("timestamp")
+
+		err = manager.AppendNote(title, text, opts)
+		errHandler(cmd, err)
+
+		// Print success message
+		cmd.Printf("appended to note \"%s\"\n", title)
+	},
+}
+
+func init() {
+	appendCmd.Flags().String("heading", "", "append under this heading, such as 'Log' or '### Log' (created if missing)")
+	appendCmd.Flags().BoolP("bullet", "b", false, "add the text as a bullet entry")
+	appendCmd.Flags().BoolP("timestamp", "t", false, "prefix the text with the current time")
+}
@@ -0,0 +1,75 @@
+// 'backup' command writes every note, its metadata, and the config to an archive
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup [file.tar.gz|file.zip]",
+	Short: "Back up all notes to an archive",
+	Long: `Back up all notes, their metadata, and the configuration to a single archive.
+
+If no file is provided, a timestamped archive is written to the configured
+backup directory and old archives are rotated according to the configured
+number of backups to keep.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		keep := manager.Config.BackupKeep
+		if cmd.Flags().Changed("keep") {
+			keep, _ = cmd.Flags().GetInt("keep")
+		}
+
+		// Determine where the archive is written, where only archives in the
+		// backup directory are rotated
+		var archive string
+		rotate := false
+		if len(args) == 1 {
+			// Validate file input if provided
+			archive = args[0]
+			if archive == "" {
+				cmd.PrintErr("file cannot be empty")
+				return
+			}
+		} else {
+			format, _ := cmd.Flags().GetString("format")
+
+			ext := ".tar.gz"
+			if format == "zip" {
+				ext = ".zip"
+			}
+
+			directory := manager.BackupDirectory()
+			errHandler(cmd, os.MkdirAll(directory, 0755))
+
+			archive = filepath.Join(directory, note.BackupFilename(time.Now(), ext))
+			rotate = true
+		}
+
+		// Write the archive
+		manifest, err := manager.Backup(archive)
+		errHandler(cmd, err)
+
+		// Rotate old archives in the backup directory
+		if rotate {
+			errHandler(cmd, note.RotateBackups(filepath.Dir(archive), keep))
+		}
+
+		// Print success message
+		cmd.Printf("backed up %d notes to %s\n", manifest.Notes, archive)
+	},
+}
+
+func init() {
+	backupCmd.Flags().Int("keep", 0, "number of automatic backups to keep in the backup directory (0 keeps all)")
+	backupCmd.Flags().String("format", "tar.gz", "archive format when no file is provided (tar.gz|zip)")
+}
@@ -2,17 +2,41 @@
 package main
 
 import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
 	"github.com/ethanbaker/note/pkg/note"
 	"github.com/spf13/cobra"
 )
 
+// Helper function to complete configuration keys as the first argument of a command
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	keys := []string{}
+	for _, field := range note.ConfigSchema {
+		if strings.HasPrefix(field.Key, toComplete) {
+			keys = append(keys, field.Key+"\t"+field.Description)
+		}
+	}
+
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Open the configuration JSON file",
-	Args:  cobra.NoArgs,
+	Long: `Open the configuration JSON file in the editor.
+
+Use the get, set, list, and validate subcommands to read and write individual
+keys without an editor, such as in provisioning scripts.`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get the note manager
-		manager, err := note.GetManager()
+		manager, err := getManager()
 		errHandler(cmd, err)
 
 		// Open the configuration file
@@ -23,3 +47,101 @@ var configCmd = &cobra.Command{
 		cmd.Println("configuration file edited successfully")
 	},
 }
+
+var configGetCmd = &cobra.Command{
+	Use:               "get [key]",
+	Short:             "Print the value of a configuration key",
+	ValidArgsFunction: completeConfigKeys,
+	Args:              cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		// Print the value
+		value, err := manager.Config.Get(args[0])
+		errHandler(cmd, err)
+
+		fmt.Fprintln(cmd.OutOrStdout(), value)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set [key] [value]",
+	Short:             "Validate and save the value of a configuration key",
+	ValidArgsFunction: completeConfigKeys,
+	Args:              cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		// Set and save the value
+		err = manager.Config.Set(args[0], args[1])
+		errHandler(cmd, err)
+
+		err = manager.Config.Save()
+		errHandler(cmd, err)
+
+		// Print success message
+		cmd.Printf("config key \"%s\" set successfully\n", args[0])
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List every configuration key and its value",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+
+		// Print every key as a table
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 4, ' ', 0)
+		fmt.Fprintf(w, "KEY\tVALUE\tDESCRIPTION\n")
+
+		for _, field := range manager.Config.Fields() {
+			value, _ := manager.Config.Get(field.Key)
+			if field.Secret && value != "" && !showSecrets {
+				value = "********"
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\n", field.Key, value, field.Description)
+		}
+
+		if err := w.Flush(); err != nil {
+			errHandler(cmd, err)
+		}
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check that every configuration value is valid",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		// Validate the configuration
+		err = manager.Config.Validate()
+		errHandler(cmd, err)
+
+		// Print success message
+		cmd.Println("configuration is valid")
+	},
+}
+
+func init() {
+	configListCmd.Flags().Bool("show-secrets", false, "show the values of secret keys")
+
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configValidateCmd)
+}
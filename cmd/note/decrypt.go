@@ -0,0 +1,34 @@
+// 'decrypt' command stores an encrypted note as plaintext again
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var decryptCmd = &cobra.Command{
+	Use:               "decrypt [title]",
+	Short:             "Decrypt an encrypted note",
+	ValidArgsFunction: completeNotes,
+	Args:              cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Validate title input
+		title := args[0]
+		if title == "" {
+			cmd.PrintErr("title cannot be empty")
+			return
+		}
+
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+		title = resolveTitle(cmd, manager, title)
+		usePassphrase(manager, false)
+
+		// Decrypt the note
+		err = manager.DecryptNote(title)
+		errHandler(cmd, err)
+
+		// Print success message
+		cmd.Printf("note \"%s\" decrypted successfully\n", title)
+	},
+}
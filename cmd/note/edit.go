@@ -2,31 +2,112 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
 	"github.com/ethanbaker/note/pkg/note"
+	"github.com/ethanbaker/note/pkg/server"
 	"github.com/spf13/cobra"
 )
 
+// Helper function to start a live preview of a note on the provided address. The
+// returned function stops the preview
+func startPreview(cmd *cobra.Command, manager *note.Manager, title string, addr string) func() {
+	preview, err := server.NewPreview(manager, title)
+	errHandler(cmd, err)
+
+	listener, err := net.Listen("tcp", addr)
+	errHandler(cmd, err)
+
+	srv := &http.Server{
+		Handler:           preview,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Watch the note and serve the preview alongside the editor
+	ctx, cancel := context.WithCancel(context.Background())
+	go preview.Watch(ctx)
+	go srv.Serve(listener)
+
+	cmd.PrintErrf("previewing note at http://%s/\n", listener.Addr())
+
+	return func() {
+		cancel()
+
+		shutdown, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelShutdown()
+		srv.Shutdown(shutdown)
+	}
+}
+
+// Helper function to return the one-based line of a heading in a note
+func headingLine(cmd *cobra.Command, manager *note.Manager, title string, heading string) int {
+	content, err := manager.ReadNote(title)
+	errHandler(cmd, err)
+
+	for _, h := range note.ParseHeadings(content) {
+		if strings.EqualFold(strings.TrimSpace(h.Text), strings.TrimSpace(heading)) {
+			return h.Line + 1
+		}
+	}
+
+	errHandler(cmd, fmt.Errorf("heading '%s' %w in note '%s'", heading, note.ErrNotFound, title))
+	return 0
+}
+
 var editCmd = &cobra.Command{
-	Use:   "edit [title]",
+	Use:   "edit [title[#heading]]",
 	Short: "Open and edit an existing note",
-	Args:  cobra.ExactArgs(1),
+	Long: `Open an existing note in the editor.
+
+A heading can follow the title, as in 'note edit standup#blockers', to open the
+note at that heading. The editor is chosen from the editor configured for the
+file's extension, the configured editor, $VISUAL, $EDITOR, and finally vi.`,
+	ValidArgsFunction: completeNotes,
+	Args:              cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Validate title input
-		title := args[0]
+		title, heading, _ := strings.Cut(args[0], "#")
 		if title == "" {
 			cmd.PrintErr("title cannot be empty")
 			return
 		}
 
 		// Get the note manager
-		manager, err := note.GetManager()
+		manager, err := getManager()
 		errHandler(cmd, err)
+		title = resolveTitle(cmd, manager, title)
+		usePassphrase(manager, false)
+
+		// Find the line to open the note at
+		line, _ := cmd.Flags().GetInt("line")
+		if heading != "" {
+			line = headingLine(cmd, manager, title, heading)
+		}
+
+		// Start a live preview if requested
+		stop := func() {}
+		if preview, _ := cmd.Flags().GetBool("preview"); preview {
+			addr, _ := cmd.Flags().GetString("preview-addr")
+			stop = startPreview(cmd, manager, title, addr)
+		}
 
-		// Open the note
-		err = manager.OpenNote(title)
+		// Open the note, stopping the preview before errors exit the program
+		err = manager.OpenNoteAt(title, line)
+		stop()
 		errHandler(cmd, err)
 
 		// Print success message
 		cmd.Printf("note \"%s\" saved successfully\n", title)
 	},
 }
+
+func init() {
+	editCmd.Flags().Int("line", 0, "line to open the note at")
+	editCmd.Flags().Bool("preview", false, "serve a live HTML preview of the note while editing")
+	editCmd.Flags().String("preview-addr", "127.0.0.1:0", "address the live preview listens on")
+}
@@ -0,0 +1,39 @@
+// 'encrypt' command encrypts an existing note at rest
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt [title]",
+	Short: "Encrypt a note with a passphrase",
+	Long: `Encrypt a note so its content is only stored encrypted on disk.
+
+The passphrase is read from the NOTE_PASSPHRASE environment variable, or
+prompted for on the terminal. Encrypted notes are decrypted to a private
+temporary file while they are edited.`,
+	ValidArgsFunction: completeNotes,
+	Args:              cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Validate title input
+		title := args[0]
+		if title == "" {
+			cmd.PrintErr("title cannot be empty")
+			return
+		}
+
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+		title = resolveTitle(cmd, manager, title)
+		usePassphrase(manager, true)
+
+		// Encrypt the note
+		err = manager.EncryptNote(title)
+		errHandler(cmd, err)
+
+		// Print success message
+		cmd.Printf("note \"%s\" encrypted successfully\n", title)
+	},
+}
@@ -0,0 +1,55 @@
+// exit codes returned by the CLI, so scripts can tell failures apart
+package main
+
+import (
+	"errors"
+
+	"github.com/ethanbaker/note/pkg/note"
+)
+
+// Exit codes returned by the CLI. Errors that don't match a specific code exit
+// with exitFailure
+const (
+	exitFailure       = 1  // The command failed for any other reason
+	exitUsage         = 2  // The command was called with invalid arguments or flags
+	exitNotFound      = 3  // A note or vault does not exist
+	exitDuplicate     = 4  // A note or vault with the same name already exists
+	exitInvalidName   = 5  // A note or vault name contains invalid characters
+	exitAmbiguous     = 6  // A note name matches more than one note
+	exitEncrypted     = 7  // A note is encrypted, or the passphrase is incorrect
+	exitCorrupted     = 8  // An encrypted note or backup archive is corrupted
+	exitInvalidConfig = 9  // A configuration key or value is invalid
+	exitEditorFailed  = 10 // The editor could not be started or exited unsuccessfully
+	exitHookFailed    = 11 // A hook exited unsuccessfully, aborting the command
+	exitInvalidField  = 12 // A note's custom fields don't match the declared fields
+)
+
+// Errors matched to exit codes, in the order they are checked
+var exitCodes = []struct {
+	err  error
+	code int
+}{
+	{note.ErrNotFound, exitNotFound},
+	{note.ErrDuplicate, exitDuplicate},
+	{note.ErrInvalidName, exitInvalidName},
+	{note.ErrAmbiguous, exitAmbiguous},
+	{note.ErrEncrypted, exitEncrypted},
+	{note.ErrPassphrase, exitEncrypted},
+	{note.ErrCorrupted, exitCorrupted},
+	{note.ErrInvalidConfig, exitInvalidConfig},
+	{note.ErrEditorFailed, exitEditorFailed},
+	{note.ErrHookFailed, exitHookFailed},
+	{note.ErrInvalidQuery, exitUsage},
+	{note.ErrInvalidField, exitInvalidField},
+}
+
+// Return the exit code for an error
+func exitCode(err error) int {
+	for _, c := range exitCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+
+	return exitFailure
+}
@@ -10,9 +10,10 @@ import (
 )
 
 var infoCmd = &cobra.Command{
-	Use:   "info [title]",
-	Short: "Show metadata about a note",
-	Args:  cobra.ExactArgs(1),
+	Use:               "info [title]",
+	Short:             "Show metadata about a note",
+	ValidArgsFunction: completeNotes,
+	Args:              cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Validate title input
 		title := args[0]
@@ -21,29 +22,45 @@ var infoCmd = &cobra.Command{
 			return
 		}
 
+		// Read the output flags
+		opts, err := getOutputOptions(cmd, []string{"filename", "title", "createdAt", "updatedAt", "encrypted", "fields"})
+		errHandler(cmd, err)
+
 		// Get the note manager
-		manager, err := note.GetManager()
+		manager, err := getManager()
 		errHandler(cmd, err)
+		title = resolveTitle(cmd, manager, title)
 
 		// Get the note
-		note := manager.GetNote(title)
+		n := manager.GetNote(title)
 		errHandler(cmd, err)
 
 		// If note is nil, no note with the given title exists
-		if note == nil {
+		if n == nil {
 			cmd.PrintErrf(`note "%s" does not exist\n`, title)
 			return
 		}
 
+		// Print the note in a machine-readable format if requested
+		handled, err := writeNotes(cmd.OutOrStdout(), []*note.Note{n}, opts, true)
+		errHandler(cmd, err)
+		if handled {
+			return
+		}
+
 		// Print note metadata
 		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
 
-		fmt.Fprintf(w, "FILENAME\t%s\n", note.Filename)
-		fmt.Fprintf(w, "CREATED ON\t%s\n", note.CreatedAt.Format("2006-01-02 15:04:05"))
-		fmt.Fprintf(w, "LAST UPDATED\t%s\n", note.UpdatedAt.Format("2006-01-02 15:04:05"))
+		for _, field := range opts.fields {
+			fmt.Fprintf(w, "%s\t%s\n", field.label, formatValue(field.value(n), "2006-01-02 15:04:05"))
+		}
 
 		if err := w.Flush(); err != nil {
 			errHandler(cmd, err)
 		}
 	},
 }
+
+func init() {
+	addOutputFlags(infoCmd)
+}
@@ -3,24 +3,103 @@ package main
 
 import (
 	"fmt"
-	"text/tabwriter"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ethanbaker/note/pkg/note"
 	"github.com/spf13/cobra"
 )
 
+// Helper function to parse a time flag, which is either a date, an RFC 3339
+// time, or a duration before now such as '36h' or '7d'
+func parseTimeFlag(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time '%s' (expected a date, an RFC 3339 time, or a duration such as 7d)", value)
+}
+
+// Helper function to read the list options from a command's flags
+func getListOptions(cmd *cobra.Command) (note.ListOptions, error) {
+	opts := note.ListOptions{}
+
+	sort, _ := cmd.Flags().GetString("sort")
+	opts.Sort = note.SortField(sort)
+	opts.Reverse, _ = cmd.Flags().GetBool("reverse")
+	opts.Author, _ = cmd.Flags().GetString("author")
+
+	date, _ := cmd.Flags().GetString("date")
+	opts.Date = note.DateField(date)
+
+	var err error
+	since, _ := cmd.Flags().GetString("since")
+	if opts.Since, err = parseTimeFlag(since); err != nil {
+		return opts, err
+	}
+	until, _ := cmd.Flags().GetString("until")
+	if opts.Until, err = parseTimeFlag(until); err != nil {
+		return opts, err
+	}
+
+	opts.Where, _ = cmd.Flags().GetString("where")
+	opts.Limit, _ = cmd.Flags().GetInt("limit")
+	opts.Offset, _ = cmd.Flags().GetInt("offset")
+
+	return opts, opts.Validate()
+}
+
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Short:   "List existing notes",
 	Aliases: []string{"ls"},
 	Args:    cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
+		// Read the list and output flags
+		listOpts, err := getListOptions(cmd)
+		errHandler(cmd, err)
+
+		fields := []string{"filename", "createdAt", "updatedAt"}
+		if long, _ := cmd.Flags().GetBool("long"); long {
+			fields = []string{"filename", "title", "author", "createdAt", "updatedAt", "words"}
+		}
+
+		opts, err := getOutputOptions(cmd, fields)
+		errHandler(cmd, err)
+
 		// Get the note manager
-		manager, err := note.GetManager()
+		manager, err := getManager()
 		errHandler(cmd, err)
 
-		// Get all notes
-		notes := manager.GetNotes()
+		// Get the matching notes
+		notes, err := manager.ListNotes(listOpts)
+		errHandler(cmd, err)
+		if opts.needsContent() {
+			errHandler(cmd, manager.LoadContent(notes))
+		}
+
+		// Print the notes in a machine-readable format if requested
+		handled, err := writeNotes(cmd.OutOrStdout(), notes, opts, false)
+		errHandler(cmd, err)
+		if handled {
+			return
+		}
 
 		// If there are no notes, print a message and return
 		if len(notes) == 0 {
@@ -29,15 +108,21 @@ var listCmd = &cobra.Command{
 		}
 
 		// Otherwise, print all notes as a table
-		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 4, ' ', 0)
-		fmt.Fprintf(w, "FILENAME\tCREATED ON\tLAST UPDATED\n")
+		errHandler(cmd, writeTable(cmd.OutOrStdout(), notes, opts))
+	},
+}
 
-		for _, note := range notes {
-			fmt.Fprintf(w, "%s\t%s\t%s\n", note.Filename, note.CreatedAt.Format("2006-01-02"), note.UpdatedAt.Format("2006-01-02"))
-		}
+func init() {
+	listCmd.Flags().String("sort", "", "sort notes by created, updated, name, or size")
+	listCmd.Flags().BoolP("reverse", "r", false, "reverse the order of the notes")
+	listCmd.Flags().String("author", "", "only list notes by this author")
+	listCmd.Flags().String("date", "created", "time compared with --since and --until (created|updated)")
+	listCmd.Flags().String("since", "", "only list notes at or after this date, time, or duration ago (e.g. 2024-01-31, 7d)")
+	listCmd.Flags().String("until", "", "only list notes before this date, time, or duration ago")
+	listCmd.Flags().String("where", "", "only list notes matching a condition on their fields (e.g. 'status=draft AND priority>2')")
+	listCmd.Flags().Int("limit", 0, "maximum number of notes to list (0 lists all)")
+	listCmd.Flags().Int("offset", 0, "number of notes to skip")
+	listCmd.Flags().BoolP("long", "l", false, "include the author and word count of each note")
 
-		if err := w.Flush(); err != nil {
-			errHandler(cmd, err)
-		}
-	},
+	addOutputFlags(listCmd)
 }
@@ -0,0 +1,44 @@
+// logging for every command, controlled by the --verbose and --debug flags
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/ethanbaker/note/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+// Logger passed to the note manager. Logs are discarded unless --verbose or
+// --debug is set
+var logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// Helper function to write logs to stderr at the level chosen by the --verbose
+// and --debug flags
+func setupLogging(cmd *cobra.Command) {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	switch {
+	case debug:
+		logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	case verbose:
+		logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	}
+
+	slog.SetDefault(logger)
+}
+
+// Helper function to get the note manager with the command's logger, with the
+// change handlers of plugins subscribed to it
+func getManager() (*note.Manager, error) {
+	manager, err := note.GetManager(note.WithLogger(logger))
+	if err != nil {
+		return nil, err
+	}
+
+	plugin.Attach(manager)
+	return manager, nil
+}
@@ -0,0 +1,29 @@
+// 'lsp' command runs a language server for editing notes
+package main
+
+import (
+	"os"
+
+	"github.com/ethanbaker/note/pkg/lsp"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a language server for notes over stdio",
+	Long: `Run a language server for notes that communicates over stdin and stdout.
+
+Editors that support the language server protocol get completion of note names
+inside [[...]] links, go-to-definition and hover previews of linked notes,
+diagnostics for broken links and front matter, and symbols for headings.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		// Serve the editor until it exits
+		err = lsp.NewServer(manager, os.Stdin, os.Stdout).Run()
+		errHandler(cmd, err)
+	},
+}
@@ -4,24 +4,21 @@ package main
 import (
 	"os"
 
-	"github.com/ethanbaker/note/pkg/note"
+	"github.com/ethanbaker/note/pkg/plugin"
 	"github.com/spf13/cobra"
 )
 
 // Helper function to handle errors generated by the program. Errors are propagated and
-// printed to the user and then the program exits unsuccessfully
+// printed to the user and then the program exits with the error's exit code
 func errHandler(cmd *cobra.Command, err error) {
 	if err != nil {
 		cmd.PrintErr(err.Error())
 		cmd.Println()
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
 func main() {
-	// Suppress logs
-	note.SuppressLogs()
-
 	// Create new root command
 	cmd := &cobra.Command{
 		Use:   "note",
@@ -30,8 +27,20 @@ func main() {
 		CompletionOptions: cobra.CompletionOptions{
 			DisableDefaultCmd: true,
 		},
+		// Select the vault before any subcommand gets the note manager, and reject
+		// --output on commands that don't print notes
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			setupLogging(cmd)
+			checkOutputFlag(cmd)
+			selectVault(cmd)
+		},
 	}
 
+	cmd.PersistentFlags().String("vault", "", "name of the vault to use")
+	cmd.PersistentFlags().BoolP("verbose", "v", false, "log what the command does to stderr")
+	cmd.PersistentFlags().Bool("debug", false, "log every step the command takes to stderr")
+	addGlobalOutputFlag(cmd)
+
 	// Add subcommands
 	cmd.AddCommand(newCmd)
 	cmd.AddCommand(editCmd)
@@ -40,12 +49,32 @@ func main() {
 	cmd.AddCommand(configCmd)
 	cmd.AddCommand(infoCmd)
 	cmd.AddCommand(publishCmd)
+	cmd.AddCommand(backupCmd)
+	cmd.AddCommand(restoreCmd)
+	cmd.AddCommand(serveCmd)
+	cmd.AddCommand(webCmd)
+	cmd.AddCommand(lspCmd)
+	cmd.AddCommand(syncCmd)
+	cmd.AddCommand(encryptCmd)
+	cmd.AddCommand(decryptCmd)
+	cmd.AddCommand(showCmd)
+	cmd.AddCommand(appendCmd)
+	cmd.AddCommand(vaultCmd)
+	cmd.AddCommand(pluginsCmd)
+	cmd.AddCommand(watchCmd)
+	cmd.AddCommand(titleCmd)
+	cmd.AddCommand(setCmd)
+	cmd.AddCommand(queryCmd)
+	cmd.AddCommand(qCmd)
+
+	// Add the subcommands of plugins, which are compiled in by importing them
+	cmd.AddCommand(plugin.Commands()...)
 
 	// Add autocompletion support
 	cmd.CompletionOptions.DisableDefaultCmd = false
 
-	// Run the command
+	// Run the command. Errors returned by cobra come from invalid arguments or flags
 	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 }
@@ -2,14 +2,25 @@
 package main
 
 import (
-	"github.com/ethanbaker/note/pkg/note"
+	"io"
+	"regexp"
+	"strings"
+
 	"github.com/spf13/cobra"
 )
 
+// Titles that are used as the filename as they are, rather than as a slug
+var filenameTitle = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
 var newCmd = &cobra.Command{
 	Use:   "new [title]",
 	Short: "Create a new note",
-	Args:  cobra.ExactArgs(1),
+	Long: `Create a new note.
+
+A title like 'standup-notes' is used as the note's filename. Other titles, such
+as 'Café Notes' or titles in other scripts, are kept as the note's title and
+the filename is made from them, such as 'cafe-notes'.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Validate title input
 		title := args[0]
@@ -19,18 +30,54 @@ var newCmd = &cobra.Command{
 		}
 
 		// Get the note manager
-		manager, err := note.GetManager()
+		manager, err := getManager()
 		errHandler(cmd, err)
 
-		// Create a new note
-		err = manager.CreateNote(title)
+		// Create a new note, using the title as the filename if it is one
+		customTitle, _ := cmd.Flags().GetString("title")
+		if filenameTitle.MatchString(title) {
+			title = strings.ToLower(title)
+			err = manager.CreateNoteTitled(title, customTitle)
+		} else {
+			if customTitle == "" {
+				customTitle = title
+			}
+			title, err = manager.CreateNoteWithTitle(customTitle)
+		}
 		errHandler(cmd, err)
 
+		// Encrypt the empty note before it is edited if requested
+		if encrypt, _ := cmd.Flags().GetBool("encrypt"); encrypt {
+			usePassphrase(manager, true)
+
+			err = manager.EncryptNote(title)
+			errHandler(cmd, err)
+		}
+
+		// Fill the note from stdin instead of the editor if requested
+		stdin, _ := cmd.Flags().GetBool("stdin")
+		if stdin {
+			content, err := io.ReadAll(cmd.InOrStdin())
+			errHandler(cmd, err)
+
+			err = manager.UpdateNote(title, string(content))
+			errHandler(cmd, err)
+		}
+
 		// Open the newly created note
-		err = manager.OpenNote(title)
-		errHandler(cmd, err)
+		if noEdit, _ := cmd.Flags().GetBool("no-edit"); !noEdit && !stdin {
+			err = manager.OpenNote(title)
+			errHandler(cmd, err)
+		}
 
 		// Print success message
-		cmd.Println("note created successfully")
+		cmd.Printf("note '%s' created successfully\n", title)
 	},
 }
+
+func init() {
+	newCmd.Flags().Bool("encrypt", false, "encrypt the note with a passphrase")
+	newCmd.Flags().Bool("no-edit", false, "create the note without opening the editor")
+	newCmd.Flags().Bool("stdin", false, "read the note's content from stdin instead of opening the editor")
+	newCmd.Flags().String("title", "", "title of the note, in any script (defaults to the title made from the filename)")
+}
@@ -0,0 +1,382 @@
+// Machine-readable output shared by commands that print notes
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats that notes can be printed in
+const (
+	outputTable    = "table"
+	outputJSON     = "json"
+	outputYAML     = "yaml"
+	outputCSV      = "csv"
+	outputMarkdown = "markdown"
+)
+
+// noteField is a single value of a note that can be selected for output
+type noteField struct {
+	name     string                 // Name used with --fields and as the JSON, YAML, and CSV key
+	label    string                 // Label used in tables
+	metadata bool                   // Whether the field is printed by default in machine-readable formats
+	value    func(n *note.Note) any // Value of the field for a note
+}
+
+// Fields that can be printed for a note, in their default order
+var noteFields = []noteField{
+	{"filename", "FILENAME", true, func(n *note.Note) any { return n.Filename }},
+	{"title", "TITLE", true, func(n *note.Note) any { return n.Title }},
+	{"author", "AUTHOR", true, func(n *note.Note) any { return n.Author }},
+	{"createdAt", "CREATED ON", true, func(n *note.Note) any { return n.CreatedAt }},
+	{"updatedAt", "LAST UPDATED", true, func(n *note.Note) any { return n.UpdatedAt }},
+	{"encrypted", "ENCRYPTED", true, func(n *note.Note) any { return n.Encrypted }},
+	{"fields", "FIELDS", true, func(n *note.Note) any { return n.Fields }},
+	{"words", "WORDS", false, func(n *note.Note) any { return n.WordCount() }},
+	{"size", "SIZE", false, func(n *note.Note) any { return n.Size() }},
+	{"content", "CONTENT", false, func(n *note.Note) any { return n.Content }},
+}
+
+// outputOptions holds the parsed output flags of a command
+type outputOptions struct {
+	format   string             // One of the output formats
+	fields   []noteField        // Fields to print
+	selected bool               // Whether the fields were selected with --fields
+	template *template.Template // Template to print each note with, if provided
+}
+
+// Annotation of commands that print notes in the format chosen with --output
+const outputAnnotation = "output"
+
+// Helper function to add the global output flag to the root command
+func addGlobalOutputFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringP("output", "o", outputTable, "output format of commands that print notes (table|json|yaml|csv|markdown)")
+}
+
+// Helper function to reject the global output flag on commands that don't print notes
+func checkOutputFlag(cmd *cobra.Command) {
+	if cmd.Flags().Changed("output") && cmd.Annotations[outputAnnotation] == "" {
+		cmd.PrintErrf("'%s' does not support --output\n", cmd.CommandPath())
+		os.Exit(exitUsage)
+	}
+}
+
+// Helper function to add the output flags to a command that prints notes, which
+// also accepts the global output flag
+func addOutputFlags(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[outputAnnotation] = "true"
+
+	cmd.Flags().StringSlice("fields", nil, "comma-separated fields to print (filename,title,author,createdAt,updatedAt,encrypted,fields,words,size,content, or a custom field)")
+	cmd.Flags().String("format", "", "Go template used to print each note, such as '{{.Filename}} {{.UpdatedAt}}'")
+	cmd.Flags().Bool("content", false, "include note content in json, yaml, and csv output")
+}
+
+// Helper function to read the output flags of a command. The default fields are
+// used when --fields is not provided
+func getOutputOptions(cmd *cobra.Command, defaults []string) (*outputOptions, error) {
+	opts := &outputOptions{}
+
+	opts.format, _ = cmd.Flags().GetString("output")
+	switch opts.format {
+	case outputTable, outputJSON, outputYAML, outputCSV, outputMarkdown:
+	default:
+		return nil, fmt.Errorf("invalid output format '%s' (expected table, json, yaml, csv, or markdown)", opts.format)
+	}
+
+	// Select fields, where machine-readable formats default to every metadata field
+	names, _ := cmd.Flags().GetStringSlice("fields")
+	if len(names) > 0 {
+		opts.selected = true
+	} else if opts.format == outputTable {
+		names = defaults
+	} else {
+		for _, field := range noteFields {
+			if field.metadata {
+				names = append(names, field.name)
+			}
+		}
+		if content, _ := cmd.Flags().GetBool("content"); content {
+			names = append(names, "content")
+		}
+	}
+
+	for _, name := range names {
+		field, ok := lookupField(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown field '%s'", name)
+		}
+		opts.fields = append(opts.fields, field)
+	}
+
+	// Parse the template, which takes precedence over the output format
+	if format, _ := cmd.Flags().GetString("format"); format != "" {
+		tmpl, err := template.New("format").Funcs(template.FuncMap{
+			"json": func(v any) (string, error) {
+				data, err := json.Marshal(v)
+				return string(data), err
+			},
+			"date": func(layout string, t time.Time) string {
+				return t.Format(layout)
+			},
+		}).Parse(format)
+		if err != nil {
+			return nil, fmt.Errorf("invalid format template (err: %v)", err)
+		}
+		opts.template = tmpl
+	}
+
+	return opts, nil
+}
+
+// Return whether printing needs the content of notes, which is only loaded
+// when a field or the template may read it
+func (o *outputOptions) needsContent() bool {
+	if o.template != nil {
+		return true
+	}
+	for _, field := range o.fields {
+		switch strings.ToLower(field.name) {
+		case "words", "size", "content", "tags", "links", "text":
+			return true
+		}
+	}
+
+	return false
+}
+
+// Regex to verify names of custom fields selected for output
+var customFieldMatcher = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Return the field with the provided name, ignoring case. Other names select
+// a value of the notes, such as their tags or a custom field, which is empty
+// for notes that don't have it
+func lookupField(name string) (noteField, bool) {
+	name = strings.TrimSpace(name)
+	for _, field := range noteFields {
+		if strings.EqualFold(field.name, name) {
+			return field, true
+		}
+	}
+
+	if !customFieldMatcher.MatchString(name) {
+		return noteField{}, false
+	}
+
+	return noteField{name, strings.ToUpper(name), false, func(n *note.Note) any {
+		value, _ := n.Value(name)
+		return value
+	}}, true
+}
+
+// record is an ordered set of field values of a note, which keeps the order
+// of the fields when encoded
+type record struct {
+	fields []noteField
+	note   *note.Note
+}
+
+func (r record) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+
+	for i, field := range r.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(field.name)
+		value, err := json.Marshal(field.value(r.note))
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (r record) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, field := range r.fields {
+		value := &yaml.Node{}
+		if err := value.Encode(field.value(r.note)); err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: field.name}, value)
+	}
+
+	return node, nil
+}
+
+// Format a field value as text, using the provided layout for times
+func formatValue(value any, layout string) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(layout)
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		items := []string{}
+		for _, item := range v {
+			items = append(items, formatValue(item, layout))
+		}
+		return strings.Join(items, ", ")
+	case map[string]any:
+		names := []string{}
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		pairs := []string{}
+		for _, name := range names {
+			pairs = append(pairs, name+"="+formatValue(v[name], layout))
+		}
+		return strings.Join(pairs, "; ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Helper function to print notes in a machine-readable format or with a
+// template. If single is set, JSON and YAML output is a single object instead of
+// a list. It returns false if the notes should be printed as a table instead
+func writeNotes(w io.Writer, notes []*note.Note, opts *outputOptions, single bool) (bool, error) {
+	// Print each note with the template
+	if opts.template != nil {
+		for _, n := range notes {
+			if err := opts.template.Execute(w, n); err != nil {
+				return true, err
+			}
+			fmt.Fprintln(w)
+		}
+
+		return true, nil
+	}
+
+	records := []record{}
+	for _, n := range notes {
+		records = append(records, record{fields: opts.fields, note: n})
+	}
+
+	var value any = records
+	if single && len(records) == 1 {
+		value = records[0]
+	}
+
+	switch opts.format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return true, encoder.Encode(value)
+
+	case outputYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return true, err
+		}
+		return true, encoder.Close()
+
+	case outputCSV:
+		writer := csv.NewWriter(w)
+
+		header := []string{}
+		for _, field := range opts.fields {
+			header = append(header, field.name)
+		}
+		if err := writer.Write(header); err != nil {
+			return true, err
+		}
+
+		for _, n := range notes {
+			row := []string{}
+			for _, field := range opts.fields {
+				row = append(row, formatValue(field.value(n), time.RFC3339))
+			}
+			if err := writer.Write(row); err != nil {
+				return true, err
+			}
+		}
+
+		writer.Flush()
+		return true, writer.Error()
+
+	case outputMarkdown:
+		header, divider := []string{}, []string{}
+		for _, field := range opts.fields {
+			header = append(header, field.name)
+			divider = append(divider, "---")
+		}
+		fmt.Fprintf(w, "| %s |\n| %s |\n", strings.Join(header, " | "), strings.Join(divider, " | "))
+
+		for _, n := range notes {
+			row := []string{}
+			for _, field := range opts.fields {
+				row = append(row, markdownCell.Replace(formatValue(field.value(n), "2006-01-02")))
+			}
+			fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | "))
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Helper function to print notes as a table with a column for each field
+func writeTable(w io.Writer, notes []*note.Note, opts *outputOptions) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 4, ' ', 0)
+
+	labels := []string{}
+	for _, field := range opts.fields {
+		labels = append(labels, field.label)
+	}
+	fmt.Fprintln(tw, strings.Join(labels, "\t"))
+
+	for _, n := range notes {
+		values := []string{}
+		for _, field := range opts.fields {
+			value := formatValue(field.value(n), "2006-01-02")
+
+			// Mark encrypted notes unless their status is already shown
+			if field.name == "filename" && n.Encrypted && !opts.selected {
+				value += " (encrypted)"
+			}
+
+			values = append(values, value)
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// Escapes characters that would break a cell of a markdown table
+var markdownCell = strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ")
@@ -0,0 +1,74 @@
+// Passphrase prompting for encrypted notes
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"golang.org/x/term"
+)
+
+// Environment variable that provides the passphrase without prompting
+const passphraseEnv = "NOTE_PASSPHRASE"
+
+// Helper function to give the manager a passphrase source. The passphrase is read
+// from the environment or prompted for on the terminal the first time it is
+// needed. If confirm is set, the passphrase must be entered twice
+func usePassphrase(manager *note.Manager, confirm bool) {
+	var cached []byte
+
+	manager.Passphrase = func() ([]byte, error) {
+		if cached != nil {
+			return cached, nil
+		}
+
+		if env, ok := os.LookupEnv(passphraseEnv); ok && env != "" {
+			cached = []byte(env)
+			return cached, nil
+		}
+
+		passphrase, err := promptPassphrase(confirm)
+		if err != nil {
+			return nil, err
+		}
+
+		cached = passphrase
+		return cached, nil
+	}
+}
+
+// Read a passphrase from the terminal without echoing it
+func promptPassphrase(confirm bool) ([]byte, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("a passphrase is required, set %s or run in a terminal", passphraseEnv)
+	}
+	defer tty.Close()
+
+	fmt.Fprint(tty, "Passphrase: ")
+	passphrase, err := term.ReadPassword(int(tty.Fd()))
+	fmt.Fprintln(tty)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+
+	if confirm {
+		fmt.Fprint(tty, "Confirm passphrase: ")
+		again, err := term.ReadPassword(int(tty.Fd()))
+		fmt.Fprintln(tty)
+		if err != nil {
+			return nil, err
+		}
+
+		if string(again) != string(passphrase) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	return passphrase, nil
+}
@@ -0,0 +1,55 @@
+// 'plugins' command lists the plugins compiled into the tool
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethanbaker/note/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "List installed plugins",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		plugins := plugin.Plugins()
+		if len(plugins) == 0 {
+			cmd.Println("no plugins installed")
+			return
+		}
+
+		// Print each plugin with what it adds
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tEXPORTERS\tFRONT MATTER\tCOMMANDS")
+
+		for _, p := range plugins {
+			exporters, fields, commands := []string{}, []string{}, []string{}
+			for _, exporter := range p.Exporters {
+				exporters = append(exporters, exporter.Name)
+			}
+			for _, field := range p.FrontMatter {
+				fields = append(fields, field.Name)
+			}
+			for _, command := range p.Commands {
+				commands = append(commands, command.Name())
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Name, listOrDash(exporters), listOrDash(fields), listOrDash(commands))
+		}
+
+		if err := w.Flush(); err != nil {
+			errHandler(cmd, err)
+		}
+	},
+}
+
+// Helper function to join a list with commas, or return '-' if it is empty
+func listOrDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ", ")
+}
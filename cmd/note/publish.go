@@ -1,10 +1,7 @@
-// 'publish' command saves a markdown file of the note to the provided directory
+// 'publish' command saves a markdown or other exported file of the note to the provided directory
 package main
 
 import (
-	"os"
-	"path/filepath"
-
 	"github.com/ethanbaker/note/pkg/note"
 	"github.com/spf13/cobra"
 )
@@ -12,7 +9,14 @@ import (
 var publishCmd = &cobra.Command{
 	Use:   "publish [title] [directory|.]",
 	Short: "Save a note to a directory",
-	Args:  cobra.RangeArgs(1, 2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// Complete a note, then the directory to publish it to
+		if len(args) == 1 {
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		}
+		return completeNotes(cmd, args, toComplete)
+	},
+	Args: cobra.RangeArgs(1, 2),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Validate title input
 		title := args[0]
@@ -32,20 +36,20 @@ var publishCmd = &cobra.Command{
 		}
 
 		// Get the note manager
-		manager, err := note.GetManager()
+		manager, err := getManager()
 		errHandler(cmd, err)
+		title = resolveTitle(cmd, manager, title)
 
 		// If note is nil, no note with the given title exists
-		note := manager.GetNote(title)
-		if note == nil {
+		if manager.GetNote(title) == nil {
 			cmd.PrintErrf(`note "%s" does not exist\n`, title)
 			return
 		}
 
 		// Save the note to the directory
-		if err := os.WriteFile(filepath.Join(directory, note.Filename+".md"), []byte(note.AsMarkdown()), 0644); err != nil {
-			errHandler(cmd, err)
-		}
+		format, _ := cmd.Flags().GetString("format")
+		_, err = manager.PublishNoteAs(title, directory, format)
+		errHandler(cmd, err)
 
 		// Print success message
 		if directory == "." {
@@ -55,3 +59,14 @@ var publishCmd = &cobra.Command{
 		}
 	},
 }
+
+func init() {
+	publishCmd.Flags().String("format", note.DefaultExportFormat, "format to publish the note in")
+	publishCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		formats := []string{}
+		for _, exporter := range note.Exporters() {
+			formats = append(formats, exporter.Name)
+		}
+		return formats, cobra.ShellCompDirectiveNoFileComp
+	})
+}
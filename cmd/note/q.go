@@ -0,0 +1,81 @@
+// 'q' command runs saved searches
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var qCmd = &cobra.Command{
+	Use:   "q [name]",
+	Short: "Run a saved search",
+	Long: `Run a saved search, or list the saved searches if no name is provided.
+
+Searches are saved with 'note query --save <name> <expression>' or
+'note config set queries.<name> <expression>', and are stored in the "queries"
+object of the configuration file.`,
+	ValidArgsFunction: completeSavedQueries,
+	Args:              cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		// List the saved searches if no name is provided
+		if len(args) == 0 {
+			if len(manager.Config.Queries) == 0 {
+				cmd.Println("No saved searches found")
+				return
+			}
+
+			names := []string{}
+			for name := range manager.Config.Queries {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 4, ' ', 0)
+			fmt.Fprintln(w, "NAME\tQUERY")
+			for _, name := range names {
+				fmt.Fprintf(w, "%s\t%s\n", name, manager.Config.Queries[name])
+			}
+			errHandler(cmd, w.Flush())
+			return
+		}
+
+		q, err := manager.Config.SavedQuery(args[0])
+		errHandler(cmd, err)
+
+		runQuery(cmd, manager, q)
+	},
+}
+
+// Helper function to complete the names of saved searches
+func completeSavedQueries(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	manager, err := getManager()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	completions := []string{}
+	for name, query := range manager.Config.Queries {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name+"\t"+query)
+		}
+	}
+	sort.Strings(completions)
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	addOutputFlags(qCmd)
+}
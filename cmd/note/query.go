@@ -0,0 +1,101 @@
+// 'query' command finds notes with the query language
+package main
+
+import (
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/spf13/cobra"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query [expression]",
+	Short: "Find notes with a query",
+	Long: `Find notes with a query, such as:
+
+  note query 'status=draft AND priority>2'
+  note query '#work "weekly review" created>=-7d'
+  note query 'TABLE status, due WHERE [[roadmap]] OR backlinks=roadmap SORT due DESC LIMIT 10'
+
+Queries compare metadata and custom fields with =, !=, >, >=, <, <=, and ~
+(contains), match tags with #tag and links with [[note]], and search for other
+words and quoted phrases in the title and content of notes. Conditions are
+joined with AND (which can be left out), OR, NOT, and parentheses. TABLE selects
+the columns that are printed, SORT orders the notes, and LIMIT keeps the first
+notes. Use --save to keep the query as a saved search run with 'note q <name>'.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		q, err := note.ParseQuery(args[0])
+		errHandler(cmd, err)
+
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		// Save the query if requested
+		if name, _ := cmd.Flags().GetString("save"); name != "" {
+			err = manager.Config.Set("queries."+name, args[0])
+			errHandler(cmd, err)
+
+			err = manager.Config.Save()
+			errHandler(cmd, err)
+
+			cmd.PrintErrf("search '%s' saved successfully\n", name)
+		}
+
+		runQuery(cmd, manager, q)
+	},
+}
+
+// Helper function to run a query and print the matching notes. The columns of
+// the query are printed unless fields are selected with --fields
+func runQuery(cmd *cobra.Command, manager *note.Manager, q *note.Query) {
+	result := manager.Query(q)
+
+	opts, err := getOutputOptions(cmd, result.Columns)
+	errHandler(cmd, err)
+	if !opts.selected {
+		opts.fields = queryFields(result)
+	}
+	if opts.needsContent() {
+		errHandler(cmd, manager.LoadContent(result.Notes))
+	}
+
+	// Print the notes in a machine-readable format if requested
+	handled, err := writeNotes(cmd.OutOrStdout(), result.Notes, opts, false)
+	errHandler(cmd, err)
+	if handled {
+		return
+	}
+
+	// If there are no notes, print a message and return
+	if len(result.Notes) == 0 {
+		cmd.Println("No notes found")
+		return
+	}
+
+	errHandler(cmd, writeTable(cmd.OutOrStdout(), result.Notes, opts))
+}
+
+// Return output fields for the columns of a query's result
+func queryFields(result *note.QueryResult) []noteField {
+	rows := map[*note.Note][]any{}
+	for i, n := range result.Notes {
+		rows[n] = result.Rows[i]
+	}
+
+	fields := []noteField{}
+	for i, column := range result.Columns {
+		i := i
+
+		field, _ := lookupField(column)
+		field.value = func(n *note.Note) any { return rows[n][i] }
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func init() {
+	queryCmd.Flags().String("save", "", "save the query as a search with this name")
+
+	addOutputFlags(queryCmd)
+}
@@ -2,15 +2,15 @@
 package main
 
 import (
-	"github.com/ethanbaker/note/pkg/note"
 	"github.com/spf13/cobra"
 )
 
 var removeCmd = &cobra.Command{
-	Use:     "remove [title]",
-	Short:   "Removes an note",
-	Args:    cobra.ExactArgs(1),
-	Aliases: []string{"rm"},
+	Use:               "remove [title]",
+	Short:             "Removes an note",
+	Args:              cobra.ExactArgs(1),
+	Aliases:           []string{"rm"},
+	ValidArgsFunction: completeNotes,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Validate title input
 		title := args[0]
@@ -20,9 +20,12 @@ var removeCmd = &cobra.Command{
 		}
 
 		// Get the note manager
-		manager, err := note.GetManager()
+		manager, err := getManager()
 		errHandler(cmd, err)
 
+		// Only remove loosely matched notes once the user confirms them
+		title = resolveExactTitle(cmd, manager, title)
+
 		// Remove the note
 		err = manager.DeleteNote(title)
 		errHandler(cmd, err)
@@ -0,0 +1,135 @@
+// Note name resolution and shell completion shared by commands that take a note
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Helper function to resolve a title to the filename of an existing note. Titles
+// can be a prefix, substring, or fuzzy match of a filename. If several notes
+// match, the user picks one on the terminal
+func resolveTitle(cmd *cobra.Command, manager *note.Manager, title string) string {
+	filename, err := manager.ResolveNote(title)
+
+	ambiguous := &note.AmbiguousError{}
+	if errors.As(err, &ambiguous) {
+		filename, err = promptChoice(ambiguous.Matches)
+		if err != nil {
+			err = fmt.Errorf("%w (%v)", ambiguous, err)
+		}
+	}
+	errHandler(cmd, err)
+
+	return filename
+}
+
+// Helper function to resolve a title to the filename of a note that a command
+// changes. Notes that only loosely match the title, rather than by filename or
+// title, are only used once the user confirms them on the terminal, so scripts
+// never write to a note they didn't name
+func resolveExactTitle(cmd *cobra.Command, manager *note.Manager, title string) string {
+	filename := resolveTitle(cmd, manager, title)
+	if filename == strings.ToLower(title) || strings.EqualFold(manager.GetNote(filename).Title, title) {
+		return filename
+	}
+
+	if !confirmMatch(title, filename) {
+		errHandler(cmd, fmt.Errorf("note with name '%s' %w (did you mean '%s'?)", strings.ToLower(title), note.ErrNotFound, filename))
+	}
+
+	return filename
+}
+
+// Ask the user to pick one of the provided filenames on the terminal
+func promptChoice(filenames []string) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", fmt.Errorf("use a longer name to pick one")
+	}
+	defer tty.Close()
+
+	if !term.IsTerminal(int(tty.Fd())) {
+		return "", fmt.Errorf("use a longer name to pick one")
+	}
+
+	for i, filename := range filenames {
+		fmt.Fprintf(tty, "%d) %s\n", i+1, filename)
+	}
+	fmt.Fprintf(tty, "Select a note [1-%d]: ", len(filenames))
+
+	line, err := bufio.NewReader(tty).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	choice, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || choice < 1 || choice > len(filenames) {
+		return "", fmt.Errorf("invalid selection '%s'", strings.TrimSpace(line))
+	}
+
+	return filenames[choice-1], nil
+}
+
+// Helper function to complete note names as the first argument of a command
+func completeNotes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	manager, err := getManager()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	// Offer every note that starts with the input, falling back to close matches
+	completions := []string{}
+	notes, err := manager.ListNotes(note.ListOptions{})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	for _, n := range notes {
+		if strings.HasPrefix(n.Filename, strings.ToLower(toComplete)) {
+			completions = append(completions, n.Filename+"\t"+n.UpdatedAt.Format("2006-01-02"))
+		}
+	}
+	if len(completions) == 0 {
+		for _, n := range manager.MatchNotes(toComplete) {
+			completions = append(completions, n.Filename+"\t"+n.UpdatedAt.Format("2006-01-02"))
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// Ask the user to confirm using a note that only loosely matched their input.
+// Without a terminal the match is never confirmed
+func confirmMatch(title string, filename string) bool {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	defer tty.Close()
+
+	if !term.IsTerminal(int(tty.Fd())) {
+		return false
+	}
+
+	fmt.Fprintf(tty, "'%s' matched note '%s', continue? [y/N]: ", title, filename)
+
+	line, err := bufio.NewReader(tty).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
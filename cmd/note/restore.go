@@ -0,0 +1,53 @@
+// 'restore' command restores notes from a backup archive
+package main
+
+import (
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [archive]",
+	Short: "Restore notes from a backup archive",
+	Long: `Restore notes from a backup archive created with 'note backup'.
+
+The archive is always verified before it is applied. In merge mode, notes that
+are missing or older locally are taken from the archive. In replace mode, the
+archive replaces every note and the configuration.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Validate archive input
+		archive := args[0]
+		if archive == "" {
+			cmd.PrintErr("archive cannot be empty")
+			return
+		}
+
+		// Only verify the archive if requested
+		if verify, _ := cmd.Flags().GetBool("verify"); verify {
+			manifest, err := note.VerifyBackup(archive)
+			errHandler(cmd, err)
+
+			cmd.Printf("archive is valid (version %d, created %s)\n", manifest.Version, manifest.CreatedAt.Format("2006-01-02 15:04:05"))
+			return
+		}
+
+		mode, _ := cmd.Flags().GetString("mode")
+
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		// Restore the archive
+		_, err = manager.Restore(archive, note.RestoreMode(mode))
+		errHandler(cmd, err)
+
+		// Print success message
+		cmd.Println("notes restored successfully")
+	},
+}
+
+func init() {
+	restoreCmd.Flags().String("mode", string(note.RestoreMerge), "how to apply the archive (merge|replace)")
+	restoreCmd.Flags().Bool("verify", false, "only verify the archive without restoring it")
+}
@@ -0,0 +1,94 @@
+// 'serve' command exposes notes over a local HTTP API
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/server"
+	"github.com/spf13/cobra"
+)
+
+// Helper function to serve the provided handler until the program is interrupted.
+// If a server is provided and --watch is set, its manager is kept in sync with
+// changes made to note files while serving
+func runServer(cmd *cobra.Command, addr string, handler http.Handler, api *server.Server) {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Shut the server down when interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if api != nil {
+		api.SetAddress(addr)
+	}
+
+	// Stop serving if watching fails, keeping its error to exit with
+	watchErr := make(chan error, 1)
+	if watch, _ := cmd.Flags().GetBool("watch"); watch && api != nil {
+		interval := watchInterval(cmd)
+		go func() {
+			if err := api.Watch(ctx, interval); err != nil {
+				watchErr <- err
+				stop()
+			}
+		}()
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		errHandler(cmd, err)
+	}
+
+	select {
+	case err := <-watchErr:
+		errHandler(cmd, err)
+	default:
+	}
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve notes over a local HTTP API",
+	Long: `Serve notes over a local HTTP API.
+
+The API exposes JSON endpoints to list, get, create, update, delete, search,
+and publish notes. If 'api_token' is set in the configuration, every request
+must include it as a bearer token. Notes are published to 'publish_directory',
+and requests from web pages on other origins are rejected. With --watch, changes made to note files
+outside of note are picked up while serving.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		addr, _ := cmd.Flags().GetString("addr")
+
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		// Serve the API
+		cmd.Printf("serving notes on %s\n", addr)
+		api := server.New(manager)
+		runServer(cmd, addr, api, api)
+	},
+}
+
+func init() {
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", "address to listen on")
+	serveCmd.Flags().Bool("watch", false, "keep notes in sync with changes made to their files while serving")
+	serveCmd.Flags().Duration("interval", 2*time.Second, "time between scans of the notes directory with --watch")
+}
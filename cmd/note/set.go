@@ -0,0 +1,79 @@
+// 'set' command shows or changes the custom fields of a note
+package main
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var setCmd = &cobra.Command{
+	Use:   "set [note] [field=value...]",
+	Short: "Show or change the custom fields of a note",
+	Long: `Show or change the custom fields of a note, such as status or priority.
+
+Values are parsed with the type of the field declared in the config's "fields"
+schema, where list values are separated by commas. An empty value, such as
+'status=', removes the field. Without any fields, the note's fields are printed.`,
+	ValidArgsFunction: completeNotes,
+	Args:              cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		// Only change the fields of loosely matched notes once the user confirms them
+		var filename string
+		if len(args) == 1 {
+			filename = resolveTitle(cmd, manager, args[0])
+		} else {
+			filename = resolveExactTitle(cmd, manager, args[0])
+		}
+
+		// If note is nil, no note with the given title exists
+		n := manager.GetNote(filename)
+		if n == nil {
+			cmd.PrintErrf(`note "%s" does not exist\n`, filename)
+			return
+		}
+
+		// Print the fields if none are provided
+		if len(args) == 1 {
+			names := []string{}
+			for name := range n.Fields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				cmd.Printf("%s: %s\n", name, formatValue(n.Fields[name], "2006-01-02"))
+			}
+			return
+		}
+
+		// Parse the fields, where empty values remove the field
+		values := map[string]any{}
+		for _, arg := range args[1:] {
+			name, text, ok := strings.Cut(arg, "=")
+			if !ok {
+				cmd.PrintErrf("invalid field '%s' (expected field=value)\n", arg)
+				os.Exit(exitUsage)
+			}
+
+			if text == "" {
+				values[name] = nil
+				continue
+			}
+
+			values[name], err = manager.Config.ParseFieldValue(name, text)
+			errHandler(cmd, err)
+		}
+
+		err = manager.SetFields(filename, values)
+		errHandler(cmd, err)
+
+		cmd.Printf("note '%s' updated successfully\n", filename)
+	},
+}
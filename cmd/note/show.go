@@ -0,0 +1,105 @@
+// 'show' command prints a note to the terminal
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Pager used when $PAGER is not set
+const defaultPager = "less -R"
+
+// Helper function to write output through the user's pager. The output is
+// written directly if the pager cannot be started
+func page(cmd *cobra.Command, output string) error {
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = strings.Fields(defaultPager)
+	}
+
+	if _, err := exec.LookPath(pager[0]); err != nil {
+		fmt.Fprint(cmd.OutOrStdout(), output)
+		return nil
+	}
+
+	process := exec.Command(pager[0], pager[1:]...)
+	process.Stdin = strings.NewReader(output)
+	process.Stdout = os.Stdout
+	process.Stderr = os.Stderr
+
+	// Let less pass styling through and exit if the note fits on one screen
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		process.Env = append(os.Environ(), "LESS=FRX")
+	}
+
+	return process.Run()
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show [title]",
+	Short: "Print a note to the terminal",
+	Long: `Render a note's Markdown for the terminal.
+
+When output is a terminal, headings, emphasis, and code are styled, code blocks
+are highlighted, links can be clicked, and the note is shown through $PAGER.
+Otherwise the note is printed as plain text.`,
+	ValidArgsFunction: completeNotes,
+	Args:              cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Validate title input
+		title := args[0]
+		if title == "" {
+			cmd.PrintErr("title cannot be empty")
+			return
+		}
+
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+		title = resolveTitle(cmd, manager, title)
+		usePassphrase(manager, false)
+
+		// Read the note, decrypting it if needed
+		content, err := manager.ReadNote(title)
+		errHandler(cmd, err)
+
+		shown := *manager.GetNote(title)
+		shown.Content = content
+
+		// Style the note and page it only when writing to a terminal
+		fd := int(os.Stdout.Fd())
+		tty := cmd.OutOrStdout() == os.Stdout && term.IsTerminal(fd)
+
+		plain, _ := cmd.Flags().GetBool("plain")
+		_, noColor := os.LookupEnv("NO_COLOR")
+		color := tty && !plain && !noColor
+
+		width, _ := cmd.Flags().GetInt("width")
+		if width <= 0 {
+			width = 80
+			if columns, _, err := term.GetSize(fd); err == nil && tty {
+				width = columns
+			}
+		}
+
+		output := shown.AsTerminal(width, color)
+
+		if noPager, _ := cmd.Flags().GetBool("no-pager"); tty && !noPager {
+			errHandler(cmd, page(cmd, output))
+			return
+		}
+
+		fmt.Fprint(cmd.OutOrStdout(), output)
+	},
+}
+
+func init() {
+	showCmd.Flags().Bool("plain", false, "print plain text without styling")
+	showCmd.Flags().Bool("no-pager", false, "print directly instead of through $PAGER")
+	showCmd.Flags().Int("width", 0, "width to wrap text to (defaults to the terminal width)")
+}
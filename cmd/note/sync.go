@@ -0,0 +1,54 @@
+// 'sync' command exchanges notes with a remote directory or git repository
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [remote]",
+	Short: "Sync notes with a remote directory or bare git repository",
+	Long: `Sync notes and their metadata in both directions with a remote.
+
+The remote can be a directory, such as a mounted share, or the path of a bare
+git repository. Notes edited on both sides since the last sync are merged line
+by line. If the edits cannot be merged, the most recently updated version is
+kept and the other version is saved as a conflict copy.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Validate remote input
+		remote := args[0]
+		if remote == "" {
+			cmd.PrintErr("remote cannot be empty")
+			return
+		}
+
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		// Sync with the remote
+		result, err := manager.Sync(remote)
+		errHandler(cmd, err)
+
+		// Print a summary of the changes
+		for _, change := range []struct {
+			label string
+			notes []string
+		}{
+			{"pushed", result.Pushed},
+			{"pulled", result.Pulled},
+			{"merged", result.Merged},
+			{"deleted", result.Deleted},
+			{"conflict copies", result.Conflicts},
+		} {
+			if len(change.notes) > 0 {
+				cmd.Printf("%s: %s\n", change.label, strings.Join(change.notes, ", "))
+			}
+		}
+
+		cmd.Println("notes synced successfully")
+	},
+}
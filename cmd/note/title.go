@@ -0,0 +1,41 @@
+// 'title' command shows or changes the title of a note
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var titleCmd = &cobra.Command{
+	Use:   "title [note] [new title]",
+	Short: "Show or change the title of a note",
+	Long: `Show or change the title of a note.
+
+Titles can be written in any script and changed freely, since the note keeps
+its filename.`,
+	ValidArgsFunction: completeNotes,
+	Args:              cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+		filename := resolveTitle(cmd, manager, args[0])
+
+		// If note is nil, no note with the given title exists
+		n := manager.GetNote(filename)
+		if n == nil {
+			cmd.PrintErrf(`note "%s" does not exist\n`, filename)
+			return
+		}
+
+		// Print the title if no new title is provided
+		if len(args) == 1 {
+			cmd.Println(n.Title)
+			return
+		}
+
+		err = manager.SetTitle(filename, args[1])
+		errHandler(cmd, err)
+
+		cmd.Printf("note '%s' is now titled '%s'\n", filename, args[1])
+	},
+}
@@ -0,0 +1,180 @@
+// 'vault' command manages named sets of notes
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"text/tabwriter"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/spf13/cobra"
+)
+
+// Helper function to point the note library at the vault selected by the
+// --vault flag, $NOTE_VAULT, a vault marker, or the current vault
+func selectVault(cmd *cobra.Command) {
+	name, _ := cmd.Flags().GetString("vault")
+
+	registry, err := note.LoadVaults()
+	errHandler(cmd, err)
+
+	wd, err := os.Getwd()
+	errHandler(cmd, err)
+
+	vault, err := registry.Resolve(name, wd)
+	errHandler(cmd, err)
+
+	note.UseVault(vault)
+}
+
+var vaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Show the vault in use",
+	Long: `Vaults are named sets of notes, each with its own directory, manager file, and
+config.
+
+The vault is selected by the --vault flag, then the NOTE_VAULT environment
+variable, then the closest '.note' marker file in the working directory or its
+parents, then the vault chosen with 'note vault use'. A marker file contains the
+name of a vault, or is empty to make its directory a vault.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		registry, err := note.LoadVaults()
+		errHandler(cmd, err)
+
+		wd, err := os.Getwd()
+		errHandler(cmd, err)
+
+		name, _ := cmd.Flags().GetString("vault")
+		vault, err := registry.Resolve(name, wd)
+		errHandler(cmd, err)
+
+		directory, _, _ := vault.Paths()
+		fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", vault.Name, path.Dir(directory))
+	},
+}
+
+var vaultAddCmd = &cobra.Command{
+	Use:   "add [name] [directory]",
+	Short: "Register a new vault",
+	Long: `Register a new vault stored in a directory. Without a directory, the vault is
+stored in ~/.local/share/notes/vaults/<name>.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Validate name input
+		name := args[0]
+		if name == "" {
+			cmd.PrintErr("name cannot be empty")
+			return
+		}
+
+		home, err := os.UserHomeDir()
+		errHandler(cmd, err)
+
+		root := path.Join(home, ".local/share/notes/vaults", name)
+		if len(args) == 2 {
+			root = args[1]
+			if !path.IsAbs(root) {
+				wd, err := os.Getwd()
+				errHandler(cmd, err)
+				root = path.Join(wd, root)
+			}
+		}
+
+		// Register the vault
+		registry, err := note.LoadVaults()
+		errHandler(cmd, err)
+
+		_, err = registry.Add(name, root)
+		errHandler(cmd, err)
+
+		err = registry.Save()
+		errHandler(cmd, err)
+
+		// Print success message
+		cmd.Printf("vault \"%s\" added at %s\n", name, root)
+	},
+}
+
+var vaultListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List registered vaults",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		registry, err := note.LoadVaults()
+		errHandler(cmd, err)
+
+		// Mark the vault in use, if it can be resolved
+		wd, _ := os.Getwd()
+		name, _ := cmd.Flags().GetString("vault")
+		active, _ := registry.Resolve(name, wd)
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 2, 4, ' ', 0)
+		fmt.Fprintf(w, "\tNAME\tDIRECTORY\n")
+
+		// Vaults selected by an empty marker file aren't registered
+		vaults := registry.List()
+		if registered, err := registry.Get(active.Name); active.Name != "" && (err != nil || registered != active) {
+			vaults = append(vaults, active)
+		}
+
+		for _, vault := range vaults {
+			marker := ""
+			if vault == active {
+				marker = "*"
+			}
+
+			directory, _, _ := vault.Paths()
+			fmt.Fprintf(w, "%s\t%s\t%s\n", marker, vault.Name, path.Dir(directory))
+		}
+
+		if err := w.Flush(); err != nil {
+			errHandler(cmd, err)
+		}
+	},
+}
+
+var vaultUseCmd = &cobra.Command{
+	Use:   "use [name]",
+	Short: "Use a vault when no other vault is selected",
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		registry, err := note.LoadVaults()
+		if err != nil || len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		names := []string{}
+		for _, vault := range registry.List() {
+			names = append(names, vault.Name)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		registry, err := note.LoadVaults()
+		errHandler(cmd, err)
+
+		err = registry.Use(args[0])
+		errHandler(cmd, err)
+
+		err = registry.Save()
+		errHandler(cmd, err)
+
+		// Print success message
+		cmd.Printf("using vault \"%s\"\n", args[0])
+	},
+}
+
+func init() {
+	// Managing vaults doesn't require the selected vault to exist
+	vaultCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		setupLogging(cmd)
+		checkOutputFlag(cmd)
+	}
+
+	vaultCmd.AddCommand(vaultAddCmd)
+	vaultCmd.AddCommand(vaultListCmd)
+	vaultCmd.AddCommand(vaultUseCmd)
+}
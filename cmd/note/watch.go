@@ -0,0 +1,65 @@
+// 'watch' command keeps the manager in sync with changes made to note files outside of it
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/spf13/cobra"
+)
+
+// Helper function to print the changes a manager makes to notes
+func printChanges(cmd *cobra.Command, manager *note.Manager) {
+	manager.OnChange(func(e note.Event) {
+		if e.Type == note.EventRenamed {
+			cmd.Printf("renamed %s -> %s\n", e.Old.Filename, e.Filename)
+			return
+		}
+		cmd.Printf("%s %s\n", e.Type, e.Filename)
+	})
+}
+
+// Helper function to read the --interval flag of a command that watches notes,
+// exiting if it isn't positive
+func watchInterval(cmd *cobra.Command) time.Duration {
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if interval <= 0 {
+		cmd.PrintErrln("interval must be positive")
+		os.Exit(exitUsage)
+	}
+
+	return interval
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep notes in sync with changes made outside of note",
+	Long: `Keep notes in sync with changes made outside of note.
+
+The notes directory is scanned for files that were created, modified, renamed,
+or deleted by other editors, sync clients, or 'git pull', and the note metadata
+is updated to match. Scanning continues until the command is interrupted.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		interval := watchInterval(cmd)
+
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+		printChanges(cmd, manager)
+
+		// Watch until interrupted
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		cmd.Printf("watching %s\n", manager.Directory())
+		errHandler(cmd, manager.Watch(ctx, interval, nil))
+	},
+}
+
+func init() {
+	watchCmd.Flags().Duration("interval", 2*time.Second, "time between scans of the notes directory")
+}
@@ -0,0 +1,33 @@
+// 'web' command serves a browser-based note viewer and editor
+package main
+
+import (
+	"time"
+
+	"github.com/ethanbaker/note/pkg/server"
+	"github.com/spf13/cobra"
+)
+
+var webCmd = &cobra.Command{
+	Use:   "web",
+	Short: "Browse and edit notes in a web browser",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		addr, _ := cmd.Flags().GetString("addr")
+
+		// Get the note manager
+		manager, err := getManager()
+		errHandler(cmd, err)
+
+		// Serve the web app
+		cmd.Printf("serving notes at http://%s/\n", addr)
+		api := server.New(manager)
+		runServer(cmd, addr, server.WebHandler(api), api)
+	},
+}
+
+func init() {
+	webCmd.Flags().String("addr", "127.0.0.1:8080", "address to listen on")
+	webCmd.Flags().Bool("watch", false, "keep notes in sync with changes made to their files while serving")
+	webCmd.Flags().Duration("interval", 2*time.Second, "time between scans of the notes directory with --watch")
+}
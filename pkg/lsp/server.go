@@ -0,0 +1,625 @@
+// Package lsp implements a language server for notes that communicates over
+// the language server protocol
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/ethanbaker/note/pkg/note"
+)
+
+// Number of lines of a linked note shown when hovering over a link
+const hoverLines = 20
+
+// Server defines a struct that provides note-aware editor features, such as
+// completion of links, go-to-definition, hover previews, diagnostics, and
+// heading symbols, to any editor that supports the language server protocol
+type Server struct {
+	manager   *note.Manager     // Manager that notes are read from
+	reader    *bufio.Reader     // Reader that messages are received from
+	writer    io.Writer         // Writer that messages are sent to
+	documents map[string]string // Content of documents open in the editor, by URI
+	shutdown  bool              // Whether the client has requested a shutdown
+}
+
+// NewServer creates a new language server for the provided manager that
+// reads messages from in and writes messages to out
+func NewServer(manager *note.Manager, in io.Reader, out io.Writer) *Server {
+	return &Server{
+		manager:   manager,
+		reader:    bufio.NewReader(in),
+		writer:    out,
+		documents: map[string]string{},
+	}
+}
+
+// Run handles messages until the client sends an exit notification or the
+// input is closed
+func (s *Server) Run() error {
+	s.manager.Logger().Info("starting language server")
+
+	for {
+		msg, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			s.manager.Logger().Error("failed to read message", "err", err)
+			return err
+		}
+
+		if msg.Method == "exit" {
+			s.manager.Logger().Info("stopping language server")
+			return nil
+		}
+
+		if err := s.handle(msg); err != nil {
+			s.manager.Logger().Error("failed to write message", "err", err)
+			return err
+		}
+	}
+}
+
+// Read a single message with its header from the input
+func (s *Server) read() (*message, error) {
+	length := -1
+
+	// Read the header lines until an empty line
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid content length '%s'", value)
+			}
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("missing content length")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.reader, body); err != nil {
+		return nil, err
+	}
+
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		// Report the invalid message without stopping the server
+		return &message{Method: "$/parseError"}, nil
+	}
+
+	return msg, nil
+}
+
+// Write a single message with its header to the output
+func (s *Server) write(msg *message) error {
+	msg.JSONRPC = "2.0"
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// Send a successful response to a request
+func (s *Server) respond(id *json.RawMessage, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	return s.write(&message{ID: id, Result: data})
+}
+
+// Send an error response to a request
+func (s *Server) respondError(id *json.RawMessage, code int, text string) error {
+	return s.write(&message{ID: id, Error: &responseError{Code: code, Message: text}})
+}
+
+// Send a notification to the client
+func (s *Server) notify(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	return s.write(&message{Method: method, Params: data})
+}
+
+// Dispatch a message to its handler
+func (s *Server) handle(msg *message) error {
+	s.manager.Logger().Debug("handling message", "method", msg.Method)
+
+	// Requests after a shutdown are rejected
+	if s.shutdown && msg.ID != nil {
+		return s.respondError(msg.ID, codeInvalidRequest, "server is shutting down")
+	}
+
+	// Decode the parameters of the message into the provided value
+	decode := func(v any) bool {
+		return json.Unmarshal(msg.Params, v) == nil
+	}
+
+	switch msg.Method {
+	case "$/parseError":
+		return s.respondError(nil, codeParseError, "invalid message")
+
+	case "initialize":
+		return s.respond(msg.ID, initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync: textDocumentSyncOptions{
+					OpenClose: true,
+					Change:    syncFull,
+					Save:      saveOptions{IncludeText: true},
+				},
+				CompletionProvider:      completionOptions{TriggerCharacters: []string{"[", "#"}},
+				DefinitionProvider:      true,
+				HoverProvider:           true,
+				DocumentSymbolProvider:  true,
+				WorkspaceSymbolProvider: true,
+			},
+			ServerInfo: serverInfo{Name: "note"},
+		})
+
+	case "shutdown":
+		s.shutdown = true
+		return s.respond(msg.ID, nil)
+
+	case "textDocument/didOpen":
+		params := didOpenParams{}
+		if decode(&params) {
+			s.documents[params.TextDocument.URI] = params.TextDocument.Text
+			return s.publishDiagnostics(params.TextDocument.URI)
+		}
+
+	case "textDocument/didChange":
+		params := didChangeParams{}
+		if decode(&params) && len(params.ContentChanges) > 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+			return s.publishDiagnostics(params.TextDocument.URI)
+		}
+
+	case "textDocument/didSave":
+		params := didSaveParams{}
+		if decode(&params) {
+			if params.Text != nil {
+				s.documents[params.TextDocument.URI] = *params.Text
+			}
+
+			// Keep the manager's copy of the note in sync with the saved file
+			if n := s.noteForURI(params.TextDocument.URI); n != nil {
+				n.Content = s.documents[params.TextDocument.URI]
+			}
+
+			return s.publishDiagnostics(params.TextDocument.URI)
+		}
+
+	case "textDocument/didClose":
+		params := didCloseParams{}
+		if decode(&params) {
+			delete(s.documents, params.TextDocument.URI)
+			return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+				URI:         params.TextDocument.URI,
+				Diagnostics: []diagnostic{},
+			})
+		}
+
+	case "textDocument/completion":
+		params := textDocumentPositionParams{}
+		if !decode(&params) {
+			return s.respondError(msg.ID, codeInvalidParams, "invalid parameters")
+		}
+		return s.respond(msg.ID, s.completion(params))
+
+	case "textDocument/definition":
+		params := textDocumentPositionParams{}
+		if !decode(&params) {
+			return s.respondError(msg.ID, codeInvalidParams, "invalid parameters")
+		}
+		return s.respond(msg.ID, s.definition(params))
+
+	case "textDocument/hover":
+		params := textDocumentPositionParams{}
+		if !decode(&params) {
+			return s.respondError(msg.ID, codeInvalidParams, "invalid parameters")
+		}
+		return s.respond(msg.ID, s.hover(params))
+
+	case "textDocument/documentSymbol":
+		params := documentSymbolParams{}
+		if !decode(&params) {
+			return s.respondError(msg.ID, codeInvalidParams, "invalid parameters")
+		}
+		return s.respond(msg.ID, s.documentSymbols(params.TextDocument.URI))
+
+	case "workspace/symbol":
+		params := workspaceSymbolParams{}
+		if !decode(&params) {
+			return s.respondError(msg.ID, codeInvalidParams, "invalid parameters")
+		}
+		return s.respond(msg.ID, s.workspaceSymbols(params.Query))
+
+	default:
+		// Unknown notifications are ignored, but unknown requests must be answered
+		if msg.ID != nil {
+			return s.respondError(msg.ID, codeMethodNotFound, "method '"+msg.Method+"' not found")
+		}
+	}
+
+	return nil
+}
+
+// Return the note stored at the provided URI, or nil if the URI is not a note
+// in the manager's directory
+func (s *Server) noteForURI(uri string) *note.Note {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return nil
+	}
+
+	filename, ok := strings.CutSuffix(path.Base(u.Path), ".md")
+	if !ok {
+		return nil
+	}
+
+	n := s.manager.GetNote(strings.ToLower(filename))
+	if n == nil || path.Clean(s.manager.NotePath(n.Filename)) != path.Clean(u.Path) {
+		return nil
+	}
+
+	return n
+}
+
+// Return the URI of the note with the provided filename
+func (s *Server) uriForNote(filename string) string {
+	filepath := s.manager.NotePath(filename)
+	if !path.IsAbs(filepath) {
+		if wd, err := os.Getwd(); err == nil {
+			filepath = path.Join(wd, filepath)
+		}
+	}
+
+	return (&url.URL{Scheme: "file", Path: filepath}).String()
+}
+
+// Return the current content of the note with the provided filename, preferring
+// an unsaved version open in the editor
+func (s *Server) noteContent(n *note.Note) string {
+	if content, ok := s.documents[s.uriForNote(n.Filename)]; ok {
+		return content
+	}
+
+	return n.Content
+}
+
+// Return the line of the open document at the provided position
+func (s *Server) lineAt(uri string, line int) (string, bool) {
+	content, ok := s.documents[uri]
+	if !ok {
+		return "", false
+	}
+
+	lines := strings.Split(content, "\n")
+	if line < 0 || line >= len(lines) {
+		return "", false
+	}
+
+	return strings.TrimRight(lines[line], "\r"), true
+}
+
+// Return the link at the provided position of an open document, if any
+func (s *Server) linkAt(uri string, pos position) (note.Link, bool) {
+	line, ok := s.lineAt(uri, pos.Line)
+	if !ok {
+		return note.Link{}, false
+	}
+
+	offset := byteOffset(line, pos.Character)
+	for _, link := range note.ParseLinks(line) {
+		if offset >= link.Start && offset <= link.End {
+			link.Line = pos.Line
+			return link, true
+		}
+	}
+
+	return note.Link{}, false
+}
+
+// Return the range of a link within its line
+func linkRange(line string, link note.Link) textRange {
+	return textRange{
+		Start: position{Line: link.Line, Character: characterOffset(line, link.Start)},
+		End:   position{Line: link.Line, Character: characterOffset(line, link.End)},
+	}
+}
+
+// Return the line of the heading with the provided text in a note, or zero
+// if the note has no such heading
+func headingLine(content string, text string) int {
+	for _, heading := range note.ParseHeadings(content) {
+		if strings.EqualFold(heading.Text, text) {
+			return heading.Line
+		}
+	}
+
+	return 0
+}
+
+// Complete note filenames inside '[[' and headings after '[[note#'
+func (s *Server) completion(params textDocumentPositionParams) completionList {
+	result := completionList{Items: []completionItem{}}
+
+	line, ok := s.lineAt(params.TextDocument.URI, params.Position.Line)
+	if !ok {
+		return result
+	}
+
+	// Find an unclosed link before the cursor
+	before := line[:byteOffset(line, params.Position.Character)]
+	start := strings.LastIndex(before, "[[")
+	if start < 0 || strings.Contains(before[start:], "]]") {
+		return result
+	}
+	typed := before[start+2:]
+
+	// Replace everything typed inside the link so far
+	replace := func(prefix int) textRange {
+		return textRange{
+			Start: position{Line: params.Position.Line, Character: characterOffset(line, start+2+prefix)},
+			End:   params.Position,
+		}
+	}
+
+	if target, _, ok := strings.Cut(typed, "#"); ok {
+		// Complete headings of the linked note
+		n := s.manager.GetNote(strings.ToLower(strings.TrimSpace(target)))
+		if n == nil {
+			return result
+		}
+
+		for _, heading := range note.ParseHeadings(s.noteContent(n)) {
+			result.Items = append(result.Items, completionItem{
+				Label:    heading.Text,
+				Kind:     completionKindReference,
+				Detail:   strings.Repeat("#", heading.Level) + " " + heading.Text,
+				TextEdit: &textEdit{Range: replace(len(target) + 1), NewText: heading.Text},
+			})
+		}
+
+		return result
+	}
+
+	// Complete filenames of every note
+	for _, n := range s.manager.GetNotes() {
+		item := completionItem{
+			Label:    n.Filename,
+			Kind:     completionKindFile,
+			TextEdit: &textEdit{Range: replace(0), NewText: n.Filename},
+		}
+		if headings := note.ParseHeadings(s.noteContent(n)); len(headings) > 0 {
+			item.Detail = headings[0].Text
+		}
+
+		result.Items = append(result.Items, item)
+	}
+
+	return result
+}
+
+// Return the location of the note linked at the provided position
+func (s *Server) definition(params textDocumentPositionParams) *location {
+	link, ok := s.linkAt(params.TextDocument.URI, params.Position)
+	if !ok {
+		return nil
+	}
+
+	n := s.manager.GetNote(link.Target)
+	if n == nil {
+		return nil
+	}
+
+	line := 0
+	if link.Heading != "" {
+		line = headingLine(s.noteContent(n), link.Heading)
+	}
+
+	return &location{
+		URI:   s.uriForNote(n.Filename),
+		Range: textRange{Start: position{Line: line}, End: position{Line: line}},
+	}
+}
+
+// Return a preview of the note linked at the provided position
+func (s *Server) hover(params textDocumentPositionParams) *hover {
+	link, ok := s.linkAt(params.TextDocument.URI, params.Position)
+	if !ok {
+		return nil
+	}
+
+	n := s.manager.GetNote(link.Target)
+	if n == nil {
+		return nil
+	}
+
+	// Show the beginning of the note, starting at the linked heading
+	lines := strings.Split(s.noteContent(n), "\n")
+	if link.Heading != "" {
+		lines = lines[headingLine(s.noteContent(n), link.Heading):]
+	}
+	if len(lines) > hoverLines {
+		lines = append(lines[:hoverLines], "…")
+	}
+
+	line, _ := s.lineAt(params.TextDocument.URI, params.Position.Line)
+
+	return &hover{
+		Contents: markupContent{Kind: "markdown", Value: strings.Join(lines, "\n")},
+		Range:    linkRange(line, link),
+	}
+}
+
+// Return a symbol for every heading of an open document
+func (s *Server) documentSymbols(uri string) []symbolInformation {
+	symbols := []symbolInformation{}
+
+	content, ok := s.documents[uri]
+	if !ok {
+		return symbols
+	}
+
+	lines := strings.Split(content, "\n")
+	for _, heading := range note.ParseHeadings(content) {
+		symbols = append(symbols, symbolInformation{
+			Name: heading.Text,
+			Kind: symbolKindString,
+			Location: location{
+				URI: uri,
+				Range: textRange{
+					Start: position{Line: heading.Line},
+					End:   position{Line: heading.Line, Character: characterOffset(lines[heading.Line], len(lines[heading.Line]))},
+				},
+			},
+		})
+	}
+
+	return symbols
+}
+
+// Return a symbol for every heading in every note that matches the query
+func (s *Server) workspaceSymbols(query string) []symbolInformation {
+	symbols := []symbolInformation{}
+	query = strings.ToLower(query)
+
+	for _, n := range s.manager.GetNotes() {
+		content := s.noteContent(n)
+		lines := strings.Split(content, "\n")
+
+		for _, heading := range note.ParseHeadings(content) {
+			if !strings.Contains(strings.ToLower(heading.Text), query) {
+				continue
+			}
+
+			symbols = append(symbols, symbolInformation{
+				Name:          heading.Text,
+				Kind:          symbolKindString,
+				ContainerName: n.Filename,
+				Location: location{
+					URI: s.uriForNote(n.Filename),
+					Range: textRange{
+						Start: position{Line: heading.Line},
+						End:   position{Line: heading.Line, Character: characterOffset(lines[heading.Line], len(lines[heading.Line]))},
+					},
+				},
+			})
+		}
+	}
+
+	return symbols
+}
+
+// Publish diagnostics for broken links and invalid front matter in an open document
+func (s *Server) publishDiagnostics(uri string) error {
+	content := s.documents[uri]
+	lines := strings.Split(content, "\n")
+	diagnostics := []diagnostic{}
+
+	// Check the front matter
+	if _, _, err := note.ParseFrontMatter(content); err != nil {
+		diagnostics = append(diagnostics, diagnostic{
+			Range:    textRange{Start: position{Line: 0}, End: position{Line: 0, Character: 3}},
+			Severity: severityError,
+			Source:   "note",
+			Message:  err.Error(),
+		})
+	}
+
+	// Check every link
+	for _, link := range note.ParseLinks(content) {
+		message := ""
+
+		if n := s.manager.GetNote(link.Target); n == nil {
+			message = fmt.Sprintf("note '%s' does not exist", link.Target)
+		} else if link.Heading != "" && !hasHeading(s.noteContent(n), link.Heading) {
+			message = fmt.Sprintf("note '%s' has no heading '%s'", link.Target, link.Heading)
+		}
+
+		if message != "" {
+			diagnostics = append(diagnostics, diagnostic{
+				Range:    linkRange(lines[link.Line], link),
+				Severity: severityWarning,
+				Source:   "note",
+				Message:  message,
+			})
+		}
+	}
+
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+// Return whether the provided content has a heading with the provided text
+func hasHeading(content string, text string) bool {
+	for _, heading := range note.ParseHeadings(content) {
+		if strings.EqualFold(heading.Text, text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Convert a character offset in UTF-16 code units, as used by the protocol,
+// to a byte offset within the provided line
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+
+		units++
+		if r >= 0x10000 {
+			units++
+		}
+	}
+
+	return len(line)
+}
+
+// Convert a byte offset within the provided line to a character offset in
+// UTF-16 code units, as used by the protocol
+func characterOffset(line string, offset int) int {
+	units := 0
+	for _, r := range line[:offset] {
+		units++
+		if r >= 0x10000 {
+			units++
+		}
+	}
+
+	return units
+}
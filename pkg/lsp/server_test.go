@@ -0,0 +1,144 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/lsp"
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Setup before each test by creating a manager with linked notes in a temporary directory
+func lspTestSetup(t *testing.T) *note.Manager {
+	dir := t.TempDir()
+
+	// Modify default paths to test files
+	note.ModifyDefaultDirectoryPath(path.Join(dir, "entries"))
+	note.ModifyConfigPath(path.Join(dir, "config.json"))
+	note.ModifyManagerPath(path.Join(dir, "manager.json"))
+
+	// Get the default manager
+	manager, err := note.GetManager()
+	require.Nil(t, err)
+
+	require.Nil(t, manager.CreateNote("note-1"))
+	require.Nil(t, manager.CreateNote("note-2"))
+	require.Nil(t, manager.UpdateNote("note-2", "# Note 2\n\n## Details\n\nSome details\n"))
+
+	return manager
+}
+
+// Encode messages in the wire format of the protocol
+func encode(messages ...string) io.Reader {
+	buffer := &bytes.Buffer{}
+	for _, msg := range messages {
+		fmt.Fprintf(buffer, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+
+	return buffer
+}
+
+// Decode every message written by the server
+func decode(t *testing.T, output *bytes.Buffer) []map[string]any {
+	messages := []map[string]any{}
+	reader := bufio.NewReader(output)
+
+	for {
+		header, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return messages
+		}
+		require.Nil(t, err)
+
+		length, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "Content-Length:")))
+		require.Nil(t, err)
+		_, err = reader.ReadString('\n')
+		require.Nil(t, err)
+
+		body := make([]byte, length)
+		_, err = io.ReadFull(reader, body)
+		require.Nil(t, err)
+
+		msg := map[string]any{}
+		require.Nil(t, json.Unmarshal(body, &msg))
+		messages = append(messages, msg)
+	}
+}
+
+// Test a session with an editor
+func TestSession(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager := lspTestSetup(t)
+
+	uri := (&url.URL{Scheme: "file", Path: manager.NotePath("note-1")}).String()
+	text, err := json.Marshal("# Note 1\n\nSee [[note-2#details]] and [[missing]].\n[[no")
+	require.Nil(err)
+
+	input := encode(
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`,
+		`{"jsonrpc": "2.0", "method": "initialized", "params": {}}`,
+		`{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": {"textDocument": {"uri": "`+uri+`", "text": `+string(text)+`}}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "textDocument/completion", "params": {"textDocument": {"uri": "`+uri+`"}, "position": {"line": 3, "character": 4}}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "textDocument/definition", "params": {"textDocument": {"uri": "`+uri+`"}, "position": {"line": 2, "character": 8}}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "textDocument/hover", "params": {"textDocument": {"uri": "`+uri+`"}, "position": {"line": 2, "character": 8}}}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "workspace/symbol", "params": {"query": "detail"}}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "unknown/method", "params": {}}`,
+		`{"jsonrpc": "2.0", "id": 7, "method": "shutdown"}`,
+		`{"jsonrpc": "2.0", "method": "exit"}`,
+	)
+	output := &bytes.Buffer{}
+
+	// Run the session
+	require.Nil(lsp.NewServer(manager, input, output).Run())
+
+	messages := decode(t, output)
+	require.Len(messages, 8)
+
+	// Initialization
+	capabilities := messages[0]["result"].(map[string]any)["capabilities"].(map[string]any)
+	require.Equal(true, capabilities["definitionProvider"])
+
+	// Diagnostics for the broken link
+	require.Equal("textDocument/publishDiagnostics", messages[1]["method"])
+	diagnostics := messages[1]["params"].(map[string]any)["diagnostics"].([]any)
+	require.Len(diagnostics, 1)
+	require.Equal("note 'missing' does not exist", diagnostics[0].(map[string]any)["message"])
+
+	// Completion of note names
+	items := messages[2]["result"].(map[string]any)["items"].([]any)
+	require.Len(items, 2)
+	require.Equal("note-1", items[0].(map[string]any)["label"])
+	require.Equal("note-2", items[1].(map[string]any)["label"])
+
+	// Definition of the linked note at its heading
+	definition := messages[3]["result"].(map[string]any)
+	require.True(strings.HasSuffix(definition["uri"].(string), "/note-2.md"))
+	require.Equal(float64(2), definition["range"].(map[string]any)["start"].(map[string]any)["line"])
+
+	// Hover preview of the linked note
+	contents := messages[4]["result"].(map[string]any)["contents"].(map[string]any)
+	require.True(strings.HasPrefix(contents["value"].(string), "## Details"))
+
+	// Workspace symbols for headings
+	symbols := messages[5]["result"].([]any)
+	require.Len(symbols, 1)
+	require.Equal("Details", symbols[0].(map[string]any)["name"])
+	require.Equal("note-2", symbols[0].(map[string]any)["containerName"])
+
+	// Unknown methods
+	require.NotNil(messages[6]["error"])
+
+	// Shutdown
+	require.Contains(messages[7], "result")
+	require.Nil(messages[7]["result"])
+}
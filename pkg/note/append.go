@@ -0,0 +1,145 @@
+package note
+
+import (
+	"strings"
+	"time"
+)
+
+// Layout of the timestamp added to appended entries
+const appendTimestampLayout = "2006-01-02 15:04"
+
+// AppendOptions describes how text is appended to a note
+type AppendOptions struct {
+	Heading   string // Append to the end of the section under this heading, which is created if missing
+	Bullet    bool   // Add the text as a bullet entry
+	Timestamp bool   // Prefix the text with the current time
+}
+
+// Format text as an entry according to the options
+func (o AppendOptions) entry(text string, now time.Time) []string {
+	text = strings.TrimRight(text, "\n")
+	if o.Timestamp {
+		text = "[" + now.Format(appendTimestampLayout) + "] " + text
+	}
+
+	lines := strings.Split(text, "\n")
+	if o.Bullet {
+		for i := range lines {
+			if i == 0 {
+				lines[i] = "- " + lines[i]
+			} else if lines[i] != "" {
+				lines[i] = "  " + lines[i]
+			}
+		}
+	}
+
+	return lines
+}
+
+// Return the level and text of a heading option, which can start with '#'
+// characters to choose the level of a created heading
+func parseHeadingOption(heading string) (int, string) {
+	heading = strings.TrimSpace(heading)
+
+	level := len(heading) - len(strings.TrimLeft(heading, "#"))
+	if level == 0 || level > 6 {
+		level = 2
+	}
+
+	return level, strings.TrimSpace(strings.TrimLeft(heading, "#"))
+}
+
+// Return whether a line is a markdown list item
+func isListItem(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "+ ")
+}
+
+// Insert entry lines at the end of the lines in [start, end), keeping a blank
+// line between the entry and other blocks. Consecutive bullet entries are kept
+// in the same list
+func insertEntry(lines []string, start int, end int, entry []string) []string {
+	// Drop blank lines at the end of the section
+	position := end
+	for position > start && strings.TrimSpace(lines[position-1]) == "" {
+		position--
+	}
+
+	// Separate the entry from the content before it, unless it continues a list
+	inserted := []string{}
+	continues := position > start && isListItem(entry[0]) && isListItem(lines[position-1])
+	if position > 0 && !continues {
+		inserted = append(inserted, "")
+	}
+	inserted = append(inserted, entry...)
+
+	// Keep the following content separated by a blank line
+	rest := lines[end:]
+	if len(rest) > 0 {
+		inserted = append(inserted, "")
+	}
+
+	result := append([]string{}, lines[:position]...)
+	result = append(result, inserted...)
+	return append(result, rest...)
+}
+
+// Append text to markdown content according to the options
+func appendContent(content string, text string, opts AppendOptions, now time.Time) string {
+	entry := opts.entry(text, now)
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if strings.TrimSpace(content) == "" {
+		lines = []string{}
+	}
+
+	if opts.Heading == "" {
+		return strings.Join(insertEntry(lines, 0, len(lines), entry), "\n") + "\n"
+	}
+
+	// Find the section under the heading, which ends at the next heading of the
+	// same or a higher level
+	level, title := parseHeadingOption(opts.Heading)
+	headings := ParseHeadings(strings.Join(lines, "\n"))
+
+	for i, heading := range headings {
+		if !strings.EqualFold(strings.TrimSpace(heading.Text), title) {
+			continue
+		}
+
+		end := len(lines)
+		for _, next := range headings[i+1:] {
+			if next.Level <= heading.Level {
+				end = next.Line
+				break
+			}
+		}
+
+		return strings.Join(insertEntry(lines, heading.Line+1, end, entry), "\n") + "\n"
+	}
+
+	// Create the heading at the end of the content
+	section := append([]string{strings.Repeat("#", level) + " " + title, ""}, entry...)
+	return strings.Join(insertEntry(lines, 0, len(lines), section), "\n") + "\n"
+}
+
+// Append text to an existing note and save it to storage. The text can be
+// added as a bullet entry, prefixed with the current time, and placed at the
+// end of the section under a heading
+func (m *Manager) AppendNote(filename string, text string, opts AppendOptions) error {
+	m.log().Info("appending to note", "filename", filename)
+
+	// Read the note's current content, which may be encrypted
+	content, err := m.ReadNote(filename)
+	if err != nil {
+		m.log().Error("failed to read note", "err", err)
+		return err
+	}
+
+	// Save the appended content
+	if err := m.UpdateNote(filename, appendContent(content, text, opts, m.now())); err != nil {
+		m.log().Error("failed to update note", "err", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package note_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test appending text to the end of a note
+func TestAppendNote(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	require.Nil(manager.CreateNote("log"))
+
+	// Append a paragraph, then two bullets that form a list
+	require.Nil(manager.AppendNote("log", "first line\n", note.AppendOptions{}))
+	require.Nil(manager.AppendNote("log", "one", note.AppendOptions{Bullet: true}))
+	require.Nil(manager.AppendNote("log", "two", note.AppendOptions{Bullet: true}))
+
+	require.Equal("# Log\n\nfirst line\n\n- one\n- two\n", manager.GetNote("log").Content)
+
+	// Timestamped entries start with the current time
+	require.Nil(manager.AppendNote("log", "three", note.AppendOptions{Bullet: true, Timestamp: true}))
+	require.Regexp(regexp.MustCompile(`\n- two\n- \[\d{4}-\d{2}-\d{2} \d{2}:\d{2}\] three\n$`), manager.GetNote("log").Content)
+}
+
+// Test appending text under a heading
+func TestAppendNoteHeading(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	require.Nil(manager.CreateNote("standup"))
+	require.Nil(manager.UpdateNote("standup", "# Standup\n\n## Done\n\n- shipped\n\n```\n## not a heading\n```\n\n## Todo\n\n- review\n"))
+
+	// Append to the end of an existing section
+	require.Nil(manager.AppendNote("standup", "fixed bug", note.AppendOptions{Heading: "done", Bullet: true}))
+	require.Equal("# Standup\n\n## Done\n\n- shipped\n\n```\n## not a heading\n```\n\n- fixed bug\n\n## Todo\n\n- review\n", manager.GetNote("standup").Content)
+
+	// Create a missing heading at the end of the note
+	require.Nil(manager.AppendNote("standup", "none", note.AppendOptions{Heading: "### Blockers", Bullet: true}))
+	require.Equal("# Standup\n\n## Done\n\n- shipped\n\n```\n## not a heading\n```\n\n- fixed bug\n\n## Todo\n\n- review\n\n### Blockers\n\n- none\n", manager.GetNote("standup").Content)
+
+	// Appending to a missing note fails
+	require.NotNil(manager.AppendNote("missing", "text", note.AppendOptions{}))
+}
@@ -0,0 +1,519 @@
+package note
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Version of the backup archive format written by this package. Archives with
+// a newer version cannot be restored
+const BackupVersion = 1
+
+// Prefix used for backup archives that are generated automatically
+const backupPrefix = "note-backup-"
+
+// Names of the files stored in a backup archive
+const (
+	backupManifestName = "manifest.json"
+	backupManagerName  = "manager.json"
+	backupConfigName   = "config.json"
+	backupEntriesDir   = "entries/"
+)
+
+// RestoreMode represents how a backup is applied to an existing manager
+type RestoreMode string
+
+const (
+	RestoreMerge   RestoreMode = "merge"   // Keep existing notes and add or update notes from the archive
+	RestoreReplace RestoreMode = "replace" // Replace all existing notes and the config with the archive
+)
+
+// BackupManifest describes the contents of a backup archive. Every file in the
+// archive is listed with its SHA-256 checksum so the archive can be verified
+// before it is restored
+type BackupManifest struct {
+	Version   int               `json:"version"`   // Version of the archive format
+	CreatedAt time.Time         `json:"createdAt"` // Time the archive was created
+	Notes     int               `json:"notes"`     // Number of notes in the archive
+	Checksums map[string]string `json:"checksums"` // SHA-256 checksums of each file in the archive
+}
+
+// Return the checksum of the provided data as a hex string
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Return whether the provided filepath is a zip archive. All other archives are
+// treated as gzipped tarballs
+func isZipArchive(filepath string) bool {
+	return strings.HasSuffix(strings.ToLower(filepath), ".zip")
+}
+
+// Return whether the provided filepath has an extension of a supported archive
+func isBackupArchive(filepath string) bool {
+	lower := strings.ToLower(filepath)
+	return strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// Write the provided files into an archive at the provided filepath. The
+// format of the archive is determined by the filepath's extension
+func writeArchive(filepath string, names []string, files map[string][]byte) error {
+	// Write to a temporary file first so a failed backup never replaces a good one
+	tmp, err := os.CreateTemp(path.Dir(filepath), ".note-backup-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if isZipArchive(filepath) {
+		w := zip.NewWriter(tmp)
+		for _, name := range names {
+			f, err := w.Create(name)
+			if err != nil {
+				tmp.Close()
+				return err
+			}
+			if _, err := f.Write(files[name]); err != nil {
+				tmp.Close()
+				return err
+			}
+		}
+		if err := w.Close(); err != nil {
+			tmp.Close()
+			return err
+		}
+	} else {
+		gw := gzip.NewWriter(tmp)
+		w := tar.NewWriter(gw)
+		for _, name := range names {
+			header := &tar.Header{
+				Name:    name,
+				Mode:    0600,
+				Size:    int64(len(files[name])),
+				ModTime: time.Now(),
+			}
+			if err := w.WriteHeader(header); err != nil {
+				tmp.Close()
+				return err
+			}
+			if _, err := w.Write(files[name]); err != nil {
+				tmp.Close()
+				return err
+			}
+		}
+		if err := w.Close(); err != nil {
+			tmp.Close()
+			return err
+		}
+		if err := gw.Close(); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath)
+}
+
+// Read every file from the archive at the provided filepath
+func readArchive(filepath string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+
+	if isZipArchive(filepath) {
+		r, err := zip.OpenReader(filepath)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		for _, f := range r.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+			files[f.Name] = data
+		}
+
+		return files, nil
+	}
+
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	r := tar.NewReader(gr)
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		files[header.Name] = data
+	}
+
+	return files, nil
+}
+
+// Read the archive at the provided filepath and verify its manifest. The
+// verified manifest and the files in the archive are returned
+func verifyArchive(filepath string) (*BackupManifest, map[string][]byte, error) {
+	files, err := readArchive(filepath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Parse the manifest
+	data, ok := files[backupManifestName]
+	if !ok {
+		return nil, nil, wrapf(ErrCorrupted, "backup archive is missing %s", backupManifestName)
+	}
+
+	manifest := &BackupManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, nil, wrapf(ErrCorrupted, "invalid backup manifest (err: %v)", err)
+	}
+
+	if manifest.Version < 1 || manifest.Version > BackupVersion {
+		return nil, nil, fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+
+	// Every listed file must be present with a matching checksum
+	for name, sum := range manifest.Checksums {
+		data, ok := files[name]
+		if !ok {
+			return nil, nil, wrapf(ErrCorrupted, "backup archive is missing '%s'", name)
+		}
+		if checksum(data) != sum {
+			return nil, nil, wrapf(ErrCorrupted, "checksum mismatch for '%s'", name)
+		}
+	}
+
+	// Every file in the archive must be listed in the manifest
+	for name := range files {
+		if _, ok := manifest.Checksums[name]; !ok && name != backupManifestName {
+			return nil, nil, wrapf(ErrCorrupted, "unexpected file '%s' in backup archive", name)
+		}
+	}
+
+	for _, name := range []string{backupManagerName, backupConfigName} {
+		if _, ok := files[name]; !ok {
+			return nil, nil, wrapf(ErrCorrupted, "backup archive is missing %s", name)
+		}
+	}
+
+	return manifest, files, nil
+}
+
+// VerifyBackup checks that the archive at the provided filepath is a complete
+// and uncorrupted backup, returning its manifest
+func VerifyBackup(filepath string) (*BackupManifest, error) {
+	slog.Info("verifying backup archive", "path", filepath)
+
+	manifest, _, err := verifyArchive(filepath)
+	if err != nil {
+		slog.Error("failed to verify backup archive", "err", err)
+		return nil, err
+	}
+
+	slog.Debug("successfully verified backup archive")
+	return manifest, nil
+}
+
+// Backup writes every note, the manager metadata, and the config to a single
+// archive at the provided filepath. Filepaths ending in '.zip' are written as
+// zip archives, and all other filepaths are written as gzipped tarballs
+func (m *Manager) Backup(filepath string) (*BackupManifest, error) {
+	m.log().Info("backing up notes", "path", filepath)
+
+	files := map[string][]byte{}
+	names := []string{}
+
+	// Add the manager and config files
+	managerFile, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		m.log().Error("failed to marshal manager struct", "err", err)
+		return nil, err
+	}
+	files[backupManagerName] = managerFile
+
+	configFile, err := json.MarshalIndent(m.Config, "", "    ")
+	if err != nil {
+		m.log().Error("failed to marshal config file", "err", err)
+		return nil, err
+	}
+	files[backupConfigName] = configFile
+
+	names = append(names, backupManagerName, backupConfigName)
+
+	// Add every note's file
+	for _, note := range m.Notes {
+		filepath := m.NotePath(note.Filename)
+
+		content, err := m.files().ReadFile(filepath)
+		if err != nil {
+			m.log().Error("failed to read note from file", "filename", note.Filename, "err", err)
+			return nil, err
+		}
+
+		// Encrypted notes are archived without being decrypted
+		name := backupEntriesDir + path.Base(filepath)
+		files[name] = content
+		names = append(names, name)
+	}
+
+	// Create the manifest with the checksum of every file
+	manifest := &BackupManifest{
+		Version:   BackupVersion,
+		CreatedAt: m.now(),
+		Notes:     len(m.Notes),
+		Checksums: map[string]string{},
+	}
+	for _, name := range names {
+		manifest.Checksums[name] = checksum(files[name])
+	}
+
+	manifestFile, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		m.log().Error("failed to marshal backup manifest", "err", err)
+		return nil, err
+	}
+	files[backupManifestName] = manifestFile
+	names = append([]string{backupManifestName}, names...)
+
+	// Write the archive
+	if err := writeArchive(filepath, names, files); err != nil {
+		m.log().Error("failed to write backup archive", "err", err)
+		return nil, err
+	}
+
+	m.log().Debug("successfully backed up notes", "notes", len(m.Notes))
+	return manifest, nil
+}
+
+// Restore verifies the archive at the provided filepath and applies it to the
+// manager. In merge mode, notes missing from the manager are added and notes
+// that were updated more recently in the archive replace their existing
+// version. In replace mode, the archive replaces every note and the config,
+// except for the directory notes are stored in
+func (m *Manager) Restore(filepath string, mode RestoreMode) (*BackupManifest, error) {
+	m.log().Info("restoring notes", "path", filepath, "mode", mode)
+
+	if mode != RestoreMerge && mode != RestoreReplace {
+		m.log().Error("invalid restore mode", "mode", mode)
+		return nil, fmt.Errorf("invalid restore mode '%s'", mode)
+	}
+
+	// Verify the archive before changing anything
+	manifest, files, err := verifyArchive(filepath)
+	if err != nil {
+		m.log().Error("failed to verify backup archive", "err", err)
+		return nil, err
+	}
+
+	archived := &Manager{}
+	if err := json.Unmarshal(files[backupManagerName], archived); err != nil {
+		m.log().Error("failed to parse archived manager file", "err", err)
+		return nil, err
+	}
+
+	config := &Config{}
+	if err := json.Unmarshal(files[backupConfigName], config); err != nil {
+		m.log().Error("failed to parse archived config file", "err", err)
+		return nil, err
+	}
+
+	// Attach content to every archived note. Encrypted notes keep their
+	// encrypted file, which is written as is
+	encrypted := map[string][]byte{}
+	for _, note := range archived.Notes {
+		if !filenameMatcher.MatchString(note.Filename) {
+			m.log().Error("invalid archived note name", "filename", note.Filename)
+			return nil, wrapf(ErrInvalidName, "invalid name '%s'", note.Filename)
+		}
+
+		name := backupEntriesDir + note.Filename + ".md"
+		if note.Encrypted {
+			name += ".enc"
+		}
+
+		content, ok := files[name]
+		if !ok {
+			m.log().Error("archived note has no content", "filename", note.Filename)
+			return nil, wrapf(ErrCorrupted, "backup archive is missing note '%s'", note.Filename)
+		}
+
+		if note.Encrypted {
+			encrypted[note.Filename] = content
+		} else {
+			note.Content = string(content)
+		}
+	}
+
+	before := m.snapshot()
+
+	if mode == RestoreReplace {
+		m.log().Debug("replacing existing notes")
+
+		// Remove note files that are not part of the archive
+		for _, note := range m.Notes {
+			if restored := archived.GetNote(note.Filename); restored != nil && restored.Encrypted == note.Encrypted {
+				continue
+			}
+
+			filepath := m.NotePath(note.Filename)
+			if err := m.files().Remove(filepath); err != nil && !os.IsNotExist(err) {
+				m.log().Error("failed to remove note file", "err", err)
+				return nil, err
+			}
+		}
+
+		config.Directory = m.Config.Directory
+		m.attachConfig(config)
+		m.Notes = archived.Notes
+	} else {
+		m.log().Debug("merging archived notes")
+
+		for _, note := range archived.Notes {
+			ok, index := m.contains(note.Filename)
+			if !ok {
+				m.Notes = append(m.Notes, note)
+			} else if note.UpdatedAt.After(m.Notes[index].UpdatedAt) {
+				// Remove the existing file in case the note changed between plaintext and encrypted
+				if m.Notes[index].Encrypted != note.Encrypted {
+					m.files().Remove(m.NotePath(note.Filename))
+				}
+				m.Notes[index] = note
+			} else {
+				delete(encrypted, note.Filename)
+			}
+		}
+	}
+
+	m.log().Debug("saving manager")
+
+	// Save the manager to storage
+	if err := m.files().MkdirAll(m.Directory(), 0755); err != nil {
+		m.log().Error("failed to create note directory", "err", err)
+		return nil, err
+	}
+	for filename, content := range encrypted {
+		if err := m.files().WriteFile(m.NotePath(filename), content, 0600); err != nil {
+			m.log().Error("failed to save encrypted note", "filename", filename, "err", err)
+			return nil, err
+		}
+	}
+	if err := m.Save(); err != nil {
+		m.log().Error("failed to save manager", "err", err)
+		return nil, err
+	}
+
+	// Save the restored config, which replaced the existing one
+	if mode == RestoreReplace {
+		if err := m.Config.Save(); err != nil {
+			m.log().Error("failed to save config file", "err", err)
+			return nil, err
+		}
+	}
+
+	m.emitSince(before)
+
+	m.log().Debug("successfully restored notes")
+	return manifest, nil
+}
+
+// RotateBackups removes the oldest automatically named backup archives in the
+// provided directory so that at most keep archives remain. A keep value of
+// zero or less disables rotation
+func RotateBackups(directory string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	slog.Info("rotating backups", "directory", directory, "keep", keep)
+
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		slog.Error("failed to read backup directory", "err", err)
+		return err
+	}
+
+	// Backup names contain their timestamp, so they sort from oldest to newest
+	backups := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), backupPrefix) && isBackupArchive(entry.Name()) {
+			backups = append(backups, entry.Name())
+		}
+	}
+	sort.Strings(backups)
+
+	for len(backups) > keep {
+		slog.Info("removing old backup", "backup", backups[0])
+
+		if err := os.Remove(path.Join(directory, backups[0])); err != nil {
+			slog.Error("failed to remove old backup", "err", err)
+			return err
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// BackupFilename returns a timestamped filename for an automatic backup using
+// the provided extension ('.tar.gz' or '.zip')
+func BackupFilename(t time.Time, ext string) string {
+	return backupPrefix + t.Format("20060102-150405") + ext
+}
+
+// BackupDirectory returns the directory where automatic backups are written,
+// using the config value if it is set
+func (m *Manager) BackupDirectory() string {
+	if m.Config.BackupDirectory != "" {
+		return m.Config.BackupDirectory
+	}
+
+	return path.Join(path.Dir(m.managerFile()), "backups")
+}
@@ -0,0 +1,135 @@
+package note_test
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test backing up and restoring notes in replace mode
+func TestBackupRestoreReplace(t *testing.T) {
+	for _, ext := range []string{".tar.gz", ".zip"} {
+		// Setup test
+		require := require.New(t)
+		manager, err := managerTestSetup()
+		require.Nil(err)
+
+		// Create notes and back them up
+		require.Nil(manager.CreateNote("note-1"))
+		require.Nil(manager.CreateNote("note-2"))
+
+		archive := path.Join("testing/dirty", "backup"+ext)
+		manifest, err := manager.Backup(archive)
+		require.Nil(err)
+		require.Equal(note.BackupVersion, manifest.Version)
+		require.Equal(2, manifest.Notes)
+
+		// Verify the archive
+		_, err = note.VerifyBackup(archive)
+		require.Nil(err)
+
+		// Change the notebook after the backup
+		require.Nil(manager.DeleteNote("note-1"))
+		require.Nil(manager.CreateNote("note-3"))
+
+		// Restore the archive
+		_, err = manager.Restore(archive, note.RestoreReplace)
+		require.Nil(err)
+
+		require.Len(manager.Notes, 2)
+		require.NotNil(manager.GetNote("note-1"))
+		require.NotNil(manager.GetNote("note-2"))
+		require.Nil(manager.GetNote("note-3"))
+
+		content, err := os.ReadFile("./testing/dirty/entries/note-1.md")
+		require.Nil(err)
+		require.Equal("# Note 1\n\n", string(content))
+
+		_, err = os.Stat("./testing/dirty/entries/note-3.md")
+		require.True(os.IsNotExist(err))
+	}
+}
+
+// Test restoring notes in merge mode
+func TestBackupRestoreMerge(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+
+	// Create a note and back it up
+	require.Nil(manager.CreateNote("note-1"))
+
+	archive := path.Join("testing/dirty", "backup.tar.gz")
+	_, err = manager.Backup(archive)
+	require.Nil(err)
+
+	// Change the notebook after the backup
+	require.Nil(manager.DeleteNote("note-1"))
+	require.Nil(manager.CreateNote("note-2"))
+
+	// Restore the archive, which keeps existing notes
+	_, err = manager.Restore(archive, note.RestoreMerge)
+	require.Nil(err)
+
+	require.Len(manager.Notes, 2)
+	require.NotNil(manager.GetNote("note-1"))
+	require.NotNil(manager.GetNote("note-2"))
+}
+
+// Test verifying a corrupted backup
+func TestVerifyBackupCorrupted(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+
+	require.Nil(manager.CreateNote("note-1"))
+
+	archive := path.Join("testing/dirty", "backup.zip")
+	_, err = manager.Backup(archive)
+	require.Nil(err)
+
+	// Corrupt the archive
+	data, err := os.ReadFile(archive)
+	require.Nil(err)
+	require.Nil(os.WriteFile(archive, data[:len(data)/2], 0600))
+
+	_, err = note.VerifyBackup(archive)
+	require.NotNil(err)
+
+	_, err = manager.Restore(archive, note.RestoreReplace)
+	require.NotNil(err)
+	require.NotNil(manager.GetNote("note-1"))
+}
+
+// Test rotating automatic backups
+func TestRotateBackups(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+
+	directory := "testing/dirty/backups"
+	require.Nil(os.MkdirAll(directory, 0755))
+
+	// Create several backups with increasing timestamps
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		_, err := manager.Backup(path.Join(directory, note.BackupFilename(start.Add(time.Duration(i)*time.Hour), ".tar.gz")))
+		require.Nil(err)
+	}
+
+	// Rotate the backups
+	require.Nil(note.RotateBackups(directory, 2))
+
+	entries, err := os.ReadDir(directory)
+	require.Nil(err)
+	require.Len(entries, 2)
+	require.Equal(note.BackupFilename(start.Add(3*time.Hour), ".tar.gz"), entries[0].Name())
+	require.Equal(note.BackupFilename(start.Add(4*time.Hour), ".tar.gz"), entries[1].Name())
+}
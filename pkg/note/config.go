@@ -3,9 +3,8 @@ package note
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
-	"os/exec"
 	"path"
 )
 
@@ -15,6 +14,55 @@ type Config struct {
 	Directory     string `json:"directory"`      // Directory where notes are stored
 	Editor        string `json:"editor"`         // Editor for opening notes, represented as a command
 	DefaultAuthor string `json:"default_author"` // Default author for new notes
+
+	TitleLanguage string `json:"title_language,omitempty"` // Language used to capitalize titles made from filenames, as a BCP 47 tag
+
+	Editors map[string]string `json:"editors,omitempty"` // Editors for files with specific extensions, keyed by extension
+
+	Hooks map[HookEvent]HookCommand `json:"hooks,omitempty"` // Commands run before and after notes are created, edited, deleted, and published
+
+	NoteFields map[string]NoteField `json:"fields,omitempty"` // Custom fields of notes, keyed by name
+
+	Queries map[string]string `json:"queries,omitempty"` // Saved searches, keyed by name
+
+	BackupDirectory string `json:"backup_directory,omitempty"` // Directory where automatic backups are written
+	BackupKeep      int    `json:"backup_keep,omitempty"`      // Number of automatic backups to keep (0 keeps all backups)
+
+	APIToken         string `json:"api_token,omitempty"`         // Bearer token required by the HTTP API (empty disables authentication)
+	PublishDirectory string `json:"publish_directory,omitempty"` // Directory where notes published through the HTTP API are written (empty disables publishing)
+
+	LogFile    string `json:"log_file,omitempty"`     // File where logs are written (empty disables the log file)
+	LogLevel   string `json:"log_level,omitempty"`    // Minimum level of logs written to the log file
+	LogMaxSize int    `json:"log_max_size,omitempty"` // Size in kilobytes after which the log file is rotated
+	LogKeep    int    `json:"log_keep,omitempty"`     // Number of rotated log files to keep
+
+	path   string       // Path to the config file, defaulting to the vault's config path
+	store  Store        // Store for the config file
+	logger *slog.Logger // Logger for the config's logs
+}
+
+// Return the path to the config file
+func (c *Config) filepath() string {
+	if c.path == "" {
+		return configPath
+	}
+	return c.path
+}
+
+// Return the store for the config file
+func (c *Config) files() Store {
+	if c.store == nil {
+		return OSStore{}
+	}
+	return c.store
+}
+
+// Return the logger of the config
+func (c *Config) log() *slog.Logger {
+	if c.logger == nil {
+		return slog.Default()
+	}
+	return c.logger
 }
 
 // Return a copy of the existing config
@@ -23,53 +71,106 @@ func (c *Config) Copy() *Config {
 		Directory:     c.Directory,
 		Editor:        c.Editor,
 		DefaultAuthor: c.DefaultAuthor,
+
+		TitleLanguage: c.TitleLanguage,
+
+		Editors: copyStringMap(c.Editors),
+
+		Hooks: copyHooks(c.Hooks),
+
+		NoteFields: copyNoteFields(c.NoteFields),
+
+		Queries: copyStringMap(c.Queries),
+
+		BackupDirectory: c.BackupDirectory,
+		BackupKeep:      c.BackupKeep,
+
+		APIToken:         c.APIToken,
+		PublishDirectory: c.PublishDirectory,
+
+		LogFile:    c.LogFile,
+		LogLevel:   c.LogLevel,
+		LogMaxSize: c.LogMaxSize,
+		LogKeep:    c.LogKeep,
+
+		path:   c.path,
+		store:  c.store,
+		logger: c.logger,
+	}
+}
+
+// Return a copy of a map of strings, such as the editors or saved queries
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	copied := map[string]string{}
+	for key, value := range m {
+		copied[key] = value
+	}
+
+	return copied
+}
+
+// Return a copy of a map of hooks
+func copyHooks(hooks map[HookEvent]HookCommand) map[HookEvent]HookCommand {
+	if hooks == nil {
+		return nil
 	}
+
+	copied := map[HookEvent]HookCommand{}
+	for event, command := range hooks {
+		copied[event] = append(HookCommand{}, command...)
+	}
+
+	return copied
 }
 
-// Load the configuration from the default filepath and load it into an empty struct
+// Load the configuration from its filepath into the struct
 func (c *Config) Load() error {
-	log.Printf("[INFO]: loading config file")
+	c.log().Debug("loading config file")
 
 	// Read in the provided filename
-	file, err := os.ReadFile(configPath)
+	file, err := c.files().ReadFile(c.filepath())
 	if err != nil {
-		log.Printf("[ERR]: failed to read config file (err: %v)", err)
+		c.log().Error("failed to read config file", "err", err)
 		return err
 	}
 
 	// Unmarshal the file into the Config struct
 	if err = json.Unmarshal(file, c); err != nil {
-		log.Printf("[ERR]: failed to parse config file (err: %v)", err)
+		c.log().Error("failed to parse config file", "err", err)
 		return err
 	}
 
-	log.Printf("[INFO]: successfully loaded config file")
+	c.log().Debug("successfully loaded config file")
 	return nil
 }
 
-// Save the configuration to the specified default filepath
+// Save the configuration to its filepath
 func (c *Config) Save() error {
 	// Marshal the config struct into a JSON object
 	file, err := json.MarshalIndent(c, "", "    ")
 	if err != nil {
-		log.Printf("[ERR]: failed to marshal config file (err: %v)", err)
+		c.log().Error("failed to marshal config file", "err", err)
 		return err
 	}
 
 	// Ensure the directory exists
-	err = os.MkdirAll(path.Dir(configPath), 0755)
+	err = c.files().MkdirAll(path.Dir(c.filepath()), 0755)
 	if err != nil {
-		log.Printf("[ERR]: failed to create config directory (err: %v)", err)
+		c.log().Error("failed to create config directory", "err", err)
 		return err
 	}
 
 	// Write the JSON object to the default filepath
-	if err = os.WriteFile(configPath, file, 0600); err != nil {
-		log.Printf("[ERR]: failed to save config file (err: %v)", err)
+	if err = c.files().WriteFile(c.filepath(), file, 0600); err != nil {
+		c.log().Error("failed to save config file", "err", err)
 		return err
 	}
 
-	log.Printf("[INFO]: successfully saved config file")
+	c.log().Debug("successfully saved config file")
 	return nil
 }
 
@@ -77,44 +178,48 @@ func (c *Config) Save() error {
 func NewConfig() *Config {
 	return &Config{
 		Directory:     defaultDirectoryPath,
-		Editor:        "vi",
+		Editor:        "",
 		DefaultAuthor: "Anonymous",
 	}
 }
 
 // Open the existing config file
 func (m *Manager) OpenConfig() error {
-	log.Printf("[INFO]: opening config file")
+	m.log().Info("opening config file")
 
 	// Make sure the config file exists in the manager
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		log.Printf("[ERR]: config file does not exist")
+	if _, err := m.files().Stat(m.Config.filepath()); os.IsNotExist(err) {
+		m.log().Error("config file does not exist")
 		return fmt.Errorf("config file does not exist")
 	}
 
-	log.Printf("[INFO]: opening config file in editor")
+	m.log().Debug("opening config file in editor")
 
 	// Save existing config file state in case user inputs invalid data
 	old := m.Config.Copy()
 
-	// Create open command
-	cmd := exec.Command(m.Config.Editor, configPath)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
 	// Open the file in the editor
-	if err := cmd.Run(); err != nil {
-		log.Printf("[ERR]: failed to open config file in editor (err: %v)", err)
+	if err := m.runEditor(m.Config.filepath(), 0); err != nil {
+		m.log().Error("failed to open config file in editor", "err", err)
 		return err
 	}
 
-	log.Printf("[INFO]: config edited, continuing")
-	log.Printf("[INFO]: validating config file")
+	m.log().Debug("config edited, continuing")
+	m.log().Debug("validating config file")
 
 	// Validating config file
 	if err := m.Config.Load(); err != nil {
-		log.Printf("[ERR]: failed to load updated config file (err: %v)", err)
+		m.log().Error("failed to load updated config file", "err", err)
+
+		// On error, revert to the old config
+		m.Config = old
+		m.Config.Save()
+
+		return err
+	}
+
+	if err := m.Config.Validate(); err != nil {
+		m.log().Error("updated config file is invalid", "err", err)
 
 		// On error, revert to the old config
 		m.Config = old
@@ -0,0 +1,143 @@
+package note
+
+import (
+	"sync"
+	"time"
+)
+
+// Maximum number of note files read at the same time
+const maxParallelReads = 16
+
+// Content of a note file, with the size and modification time it had when it
+// was read or written
+type cachedContent struct {
+	size    int64
+	modTime time.Time
+	content string
+}
+
+// Result of reading a note file
+type readResult struct {
+	cached cachedContent
+	err    error
+}
+
+// Read the content of a note file, using the cached content if the file's size
+// and modification time haven't changed
+func (m *Manager) readContent(filepath string, cached cachedContent, ok bool) readResult {
+	info, err := m.files().Stat(filepath)
+	if err != nil {
+		return readResult{err: err}
+	}
+
+	if ok && !info.ModTime().IsZero() && info.Size() == cached.size && info.ModTime().Equal(cached.modTime) {
+		return readResult{cached: cached}
+	}
+
+	content, err := m.files().ReadFile(filepath)
+	if err != nil {
+		return readResult{err: err}
+	}
+
+	return readResult{cached: cachedContent{size: info.Size(), modTime: info.ModTime(), content: string(content)}}
+}
+
+// LoadContent reads the content of notes that were loaded from the manager file
+// without it. Notes that already have their content, and encrypted notes, are
+// skipped. Files are read in parallel, and files that haven't changed since they
+// were last read come from a cache
+func (m *Manager) LoadContent(notes []*Note) error {
+	pending := []*Note{}
+	for _, note := range notes {
+		if note.unloaded && !note.Encrypted {
+			pending = append(pending, note)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	m.log().Debug("loading note content", "notes", len(pending))
+
+	// Read the files, with at most maxParallelReads at a time
+	results := make([]readResult, len(pending))
+	limit := make(chan struct{}, maxParallelReads)
+	wg := sync.WaitGroup{}
+
+	for i, note := range pending {
+		cached, ok := m.cache[note.Filename]
+		filepath := m.NotePath(note.Filename)
+
+		wg.Add(1)
+		limit <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			results[i] = m.readContent(filepath, cached, ok)
+			<-limit
+		}(i)
+	}
+	wg.Wait()
+
+	// Attach the content to the notes, keeping the first error
+	var err error
+	for i, note := range pending {
+		if results[i].err != nil {
+			m.log().Error("failed to read note from file", "filename", note.Filename, "err", results[i].err)
+			if err == nil {
+				err = results[i].err
+			}
+			continue
+		}
+
+		m.cacheContent(note.Filename, results[i].cached)
+		note.Content = results[i].cached.content
+		note.unloaded = false
+	}
+
+	return err
+}
+
+// Load the content of every note, logging failures. Notes whose files can't
+// be read are left empty
+func (m *Manager) loadAll() {
+	m.LoadContent(m.Notes)
+}
+
+// Record the content of a note file in the cache
+func (m *Manager) cacheContent(filename string, cached cachedContent) {
+	if m.cache == nil {
+		m.cache = map[string]cachedContent{}
+	}
+	m.cache[filename] = cached
+}
+
+// Write the content of a plaintext note to its file if the manager changed it
+// since the file was last read or written
+func (m *Manager) writeContent(note *Note) error {
+	if note.Encrypted || note.unloaded {
+		return nil
+	}
+
+	// Files edited outside the manager since they were read are left alone
+	// unless the manager changed the note too
+	filepath := m.NotePath(note.Filename)
+	if cached, ok := m.cache[note.Filename]; ok && cached.content == note.Content {
+		if _, err := m.files().Stat(filepath); err == nil {
+			return nil
+		}
+	}
+
+	m.log().Debug("saving note", "path", filepath)
+
+	if err := m.files().WriteFile(filepath, []byte(note.Content), 0600); err != nil {
+		return err
+	}
+
+	cached := cachedContent{size: int64(len(note.Content)), content: note.Content}
+	if info, err := m.files().Stat(filepath); err == nil {
+		cached.size, cached.modTime = info.Size(), info.ModTime()
+	}
+	m.cacheContent(note.Filename, cached)
+
+	return nil
+}
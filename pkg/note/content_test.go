@@ -0,0 +1,90 @@
+package note_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that note content is only read from files when it is needed
+func TestLazyContent(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	store := &note.MemoryStore{}
+	opts := []note.Option{
+		note.WithConfigPath("/vault/config.json"),
+		note.WithManagerPath("/vault/manager.json"),
+		note.WithDirectory("/vault/entries"),
+		note.WithStore(store),
+	}
+	manager, err := note.New(opts...)
+	require.Nil(err)
+
+	for i := 0; i < 50; i++ {
+		require.Nil(manager.CreateNote(fmt.Sprintf("note-%d", i)))
+	}
+	require.Nil(manager.UpdateNote("note-1", "# Links to [[note-2]]\n"))
+
+	// Listing notes doesn't read their content
+	manager, err = note.New(opts...)
+	require.Nil(err)
+	notes, err := manager.ListNotes(note.ListOptions{})
+	require.Nil(err)
+	require.Len(notes, 50)
+	require.Equal("", notes[1].Content)
+
+	// Getting a note reads its content
+	require.Equal("# Links to [[note-2]]\n", manager.GetNote("note-1").Content)
+	require.Equal("", notes[2].Content)
+
+	// Searches read every note
+	require.Len(manager.SearchNotes("note 4"), 11)
+	require.Equal("# Note 2\n\n", notes[2].Content)
+	require.Equal([]*note.Note{notes[1]}, manager.Backlinks("note-2"))
+}
+
+// Test that saving doesn't overwrite files whose content wasn't changed by the manager
+func TestSaveKeepsExternalEdits(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	store := &note.MemoryStore{}
+	opts := []note.Option{
+		note.WithConfigPath("/vault/config.json"),
+		note.WithManagerPath("/vault/manager.json"),
+		note.WithDirectory("/vault/entries"),
+		note.WithStore(store),
+	}
+	manager, err := note.New(opts...)
+	require.Nil(err)
+	require.Nil(manager.CreateNote("note-1"))
+	require.Nil(manager.CreateNote("note-2"))
+
+	manager, err = note.New(opts...)
+	require.Nil(err)
+
+	// Edit both notes outside the manager, after one of them was read
+	require.Equal("# Note 1\n\n", manager.GetNote("note-1").Content)
+	require.Nil(store.WriteFile("/vault/entries/note-1.md", []byte("external 1"), 0600))
+	require.Nil(store.WriteFile("/vault/entries/note-2.md", []byte("external 2"), 0600))
+
+	require.Nil(manager.Save())
+
+	content, err := store.ReadFile("/vault/entries/note-1.md")
+	require.Nil(err)
+	require.Equal("external 1", string(content))
+	content, err = store.ReadFile("/vault/entries/note-2.md")
+	require.Nil(err)
+	require.Equal("external 2", string(content))
+
+	// Changed files are read again instead of coming from the cache
+	require.Nil(manager.Load())
+	require.Equal("external 1", manager.GetNote("note-1").Content)
+
+	// Content changed by the manager is saved
+	require.Nil(manager.UpdateNote("note-2", "updated 2"))
+	content, err = store.ReadFile("/vault/entries/note-2.md")
+	require.Nil(err)
+	require.Equal("updated 2", string(content))
+}
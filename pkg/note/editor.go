@@ -0,0 +1,161 @@
+package note
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Editor used when neither the configuration nor the environment names one
+const fallbackEditor = "vi"
+
+// Editors that accept a '+line' argument before the file to open at a line
+var lineArgumentEditors = map[string]bool{
+	"vi":          true,
+	"vim":         true,
+	"nvim":        true,
+	"nano":        true,
+	"emacs":       true,
+	"emacsclient": true,
+	"micro":       true,
+	"kak":         true,
+}
+
+// SplitCommand splits a command into words like a POSIX shell, honoring single
+// quotes, double quotes, and backslash escapes. Variables and globs are not
+// expanded
+func SplitCommand(command string) ([]string, error) {
+	words := []string{}
+	word := strings.Builder{}
+	inWord := false
+
+	var quote rune
+	escaped := false
+
+	for _, r := range command {
+		switch {
+		case escaped:
+			// Inside double quotes, backslashes only escape a few characters
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if escaped || quote != 0 {
+		return nil, fmt.Errorf("unterminated quote or escape in command '%s'", command)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// Return the editor command used for a file. Editors configured for the file's
+// extension are preferred, then the configured editor, then $VISUAL and
+// $EDITOR, and finally vi
+func (c *Config) EditorFor(filepath string) string {
+	extension := strings.TrimPrefix(path.Ext(strings.TrimSuffix(filepath, ".enc")), ".")
+	if editor := c.Editors[extension]; editor != "" {
+		return editor
+	}
+
+	for _, editor := range []string{c.Editor, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if strings.TrimSpace(editor) != "" {
+			return editor
+		}
+	}
+
+	return fallbackEditor
+}
+
+// Build the arguments of an editor command that opens a file at a line. The
+// '{file}' and '{line}' placeholders are replaced in every word of the command.
+// Without a '{file}' placeholder the file is added as the last argument, after
+// a '+line' argument for editors known to support it
+func editorArguments(command string, filepath string, line int) ([]string, error) {
+	words, err := SplitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("editor command is empty")
+	}
+
+	if line < 1 {
+		line = 1
+	}
+
+	hasFile := false
+	for i, word := range words {
+		hasFile = hasFile || strings.Contains(word, "{file}")
+		word = strings.ReplaceAll(word, "{file}", filepath)
+		words[i] = strings.ReplaceAll(word, "{line}", strconv.Itoa(line))
+	}
+
+	if !hasFile {
+		if line > 1 && lineArgumentEditors[path.Base(words[0])] {
+			words = append(words, "+"+strconv.Itoa(line))
+		}
+		words = append(words, filepath)
+	}
+
+	return words, nil
+}
+
+// Open the file at the provided filepath in the editor for it, at the provided
+// one-based line if it is positive, and wait for the editor to exit
+func (m *Manager) runEditor(filepath string, line int) error {
+	command := m.Config.EditorFor(filepath)
+
+	args, err := editorArguments(command, filepath, line)
+	if err != nil {
+		return &EditorError{Command: []string{command}, Err: err}
+	}
+
+	m.log().Debug("running editor", "command", strings.Join(args, " "))
+
+	// Create editor command
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return &EditorError{Command: args, Err: err}
+	}
+
+	return nil
+}
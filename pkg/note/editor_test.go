@@ -0,0 +1,73 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test splitting editor commands into words
+func TestSplitCommand(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+
+	for command, expected := range map[string][]string{
+		"vi":                          {"vi"},
+		"code --wait":                 {"code", "--wait"},
+		"  emacsclient   -t ":         {"emacsclient", "-t"},
+		`"/opt/My Editor/bin" --new`:  {"/opt/My Editor/bin", "--new"},
+		`subl -w '{file}:{line}'`:     {"subl", "-w", "{file}:{line}"},
+		`my\ editor "say \"hi\" \n"`:  {"my editor", `say "hi" \n`},
+		`vim -c 'set ft=markdown' ''`: {"vim", "-c", "set ft=markdown", ""},
+	} {
+		words, err := note.SplitCommand(command)
+		require.Nil(err, command)
+		require.Equal(expected, words, command)
+	}
+
+	_, err := note.SplitCommand(`code "--wait`)
+	require.NotNil(err)
+}
+
+// Test choosing the editor for a file
+func TestEditorFor(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+
+	config := &note.Config{}
+	require.Equal("vi", config.EditorFor("note.md"))
+
+	t.Setenv("EDITOR", "nano")
+	require.Equal("nano", config.EditorFor("note.md"))
+
+	t.Setenv("VISUAL", "code --wait")
+	require.Equal("code --wait", config.EditorFor("note.md"))
+
+	config.Editor = "emacsclient -t"
+	require.Equal("emacsclient -t", config.EditorFor("note.md"))
+
+	// Editors for extensions are preferred, including for encrypted files
+	config.Editors = map[string]string{"json": "jq-edit", "md": "typora"}
+	require.Equal("jq-edit", config.EditorFor("config.json"))
+	require.Equal("typora", config.EditorFor("note.md.enc"))
+	require.Equal("emacsclient -t", config.EditorFor("notes.txt"))
+}
+
+// Test opening a note at a line with placeholders in the editor command
+func TestOpenNoteAt(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	require.Nil(manager.CreateNote("note-1"))
+
+	// The editor appends the line it was asked to open to the note
+	manager.Config.Editor = `sh -c 'printf "line %s\n" "$1" >> "$2"' sh {line} {file}`
+	require.Nil(manager.OpenNoteAt("note-1", 3))
+	require.Nil(manager.OpenNote("note-1"))
+
+	require.Equal("# Note 1\n\nline 3\nline 1\n", manager.GetNote("note-1").Content)
+}
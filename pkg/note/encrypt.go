@@ -0,0 +1,338 @@
+package note
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Header at the start of every encrypted note file, which is also
+// authenticated as additional data
+const encryptedHeader = "NOTE-ENCRYPTED v1"
+
+// Parameters used to derive a key from a passphrase with scrypt
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 16
+)
+
+// Derive a key from a passphrase and salt
+func deriveKey(passphrase []byte, salt []byte) ([]byte, error) {
+	return scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+}
+
+// Encrypt plaintext with a key derived from the passphrase. The returned file
+// contains the header followed by the base64 encoded salt, nonce, and
+// authenticated ciphertext
+func encryptContent(passphrase []byte, plaintext []byte) ([]byte, error) {
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := append(append(salt, nonce...), gcm.Seal(nil, nonce, plaintext, []byte(encryptedHeader))...)
+	return []byte(encryptedHeader + "\n" + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// Decrypt a file created by encryptContent with a key derived from the passphrase
+func decryptContent(passphrase []byte, file []byte) ([]byte, error) {
+	header, body, ok := strings.Cut(string(file), "\n")
+	if !ok || header != encryptedHeader {
+		return nil, wrapf(ErrCorrupted, "file is not an encrypted note")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(body))
+	if err != nil {
+		return nil, wrapf(ErrCorrupted, "encrypted note is corrupted")
+	}
+
+	if len(sealed) < saltLen {
+		return nil, wrapf(ErrCorrupted, "encrypted note is corrupted")
+	}
+
+	key, err := deriveKey(passphrase, sealed[:saltLen])
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < saltLen+gcm.NonceSize() {
+		return nil, wrapf(ErrCorrupted, "encrypted note is corrupted")
+	}
+	nonce := sealed[saltLen : saltLen+gcm.NonceSize()]
+
+	plaintext, err := gcm.Open(nil, nonce, sealed[saltLen+gcm.NonceSize():], []byte(encryptedHeader))
+	if err != nil {
+		return nil, wrapf(ErrPassphrase, "incorrect passphrase or corrupted note")
+	}
+
+	return plaintext, nil
+}
+
+// Return the passphrase used to encrypt and decrypt notes
+func (m *Manager) passphrase() ([]byte, error) {
+	if m.Passphrase == nil {
+		return nil, wrapf(ErrPassphrase, "a passphrase is required for encrypted notes")
+	}
+
+	return m.Passphrase()
+}
+
+// Encrypt content and write it to the file of an encrypted note
+func (m *Manager) writeEncrypted(note *Note, content []byte) error {
+	passphrase, err := m.passphrase()
+	if err != nil {
+		return err
+	}
+
+	file, err := encryptContent(passphrase, content)
+	if err != nil {
+		return err
+	}
+
+	return m.files().WriteFile(m.NotePath(note.Filename), file, 0600)
+}
+
+// Read and decrypt the file of an encrypted note
+func (m *Manager) readEncrypted(note *Note) ([]byte, error) {
+	passphrase, err := m.passphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := m.files().ReadFile(m.NotePath(note.Filename))
+	if err != nil {
+		return nil, err
+	}
+
+	return decryptContent(passphrase, file)
+}
+
+// Overwrite and remove a file in a store that held decrypted content
+func shredFile(store Store, filepath string) error {
+	if info, err := store.Stat(filepath); err == nil {
+		if err := store.WriteFile(filepath, make([]byte, info.Size()), 0600); err != nil {
+			return err
+		}
+	}
+
+	if err := store.Remove(filepath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Overwrite and remove a file in the manager's store that held decrypted content
+func (m *Manager) shred(filepath string) error {
+	return shredFile(m.files(), filepath)
+}
+
+// Encrypt an existing note with the manager's passphrase. The note's
+// plaintext file is replaced by an encrypted file, and its content is no
+// longer kept in memory
+func (m *Manager) EncryptNote(filename string) error {
+	m.log().Info("encrypting note", "filename", filename)
+
+	filename = strings.ToLower(filename)
+
+	// Make sure the filename exists in the manager
+	index, ok := -1, false
+	if ok, index = m.contains(filename); !ok {
+		m.log().Error("note not found", "filename", filename)
+		return wrapf(ErrNotFound, "note with name '%s' not found", filename)
+	}
+
+	note := m.Notes[index]
+	if note.Encrypted {
+		m.log().Error("note is already encrypted", "filename", filename)
+		return wrapf(ErrEncrypted, "note '%s' is already encrypted", filename)
+	}
+
+	if err := m.LoadContent([]*Note{note}); err != nil {
+		return err
+	}
+
+	// Write the encrypted file and save the manager before removing the
+	// plaintext file, so the note is never left without a readable file
+	old := note.Metadata
+	plaintextPath := m.NotePath(filename)
+	note.Encrypted = true
+
+	if err := m.writeEncrypted(note, []byte(note.Content)); err != nil {
+		m.log().Error("failed to encrypt note", "err", err)
+		note.Encrypted = false
+		return err
+	}
+
+	m.log().Debug("saving manager")
+
+	// Save the manager to storage, keeping the note as plaintext if it fails
+	if err := m.Save(); err != nil {
+		m.log().Error("failed to save manager", "err", err)
+		encryptedPath := m.NotePath(filename)
+		note.Encrypted = false
+		m.files().Remove(encryptedPath)
+		m.saveMetadata()
+		return err
+	}
+
+	if err := m.shred(plaintextPath); err != nil {
+		m.log().Error("failed to remove plaintext note file", "err", err)
+		return err
+	}
+	delete(m.cache, filename)
+	note.Content = ""
+
+	m.emitChange(EventUpdated, filename, &old, note)
+	return nil
+}
+
+// Decrypt an encrypted note with the manager's passphrase, storing it as a
+// plaintext file again
+func (m *Manager) DecryptNote(filename string) error {
+	m.log().Info("decrypting note", "filename", filename)
+
+	filename = strings.ToLower(filename)
+
+	// Make sure the filename exists in the manager
+	index, ok := -1, false
+	if ok, index = m.contains(filename); !ok {
+		m.log().Error("note not found", "filename", filename)
+		return wrapf(ErrNotFound, "note with name '%s' not found", filename)
+	}
+
+	note := m.Notes[index]
+	if !note.Encrypted {
+		m.log().Error("note is not encrypted", "filename", filename)
+		return wrapf(ErrEncrypted, "note '%s' is not encrypted", filename)
+	}
+
+	content, err := m.readEncrypted(note)
+	if err != nil {
+		m.log().Error("failed to decrypt note", "err", err)
+		return err
+	}
+
+	// Store the note as plaintext, which the manager saves
+	old := note.Metadata
+	encryptedPath := m.NotePath(filename)
+	note.Encrypted = false
+	note.Content = string(content)
+	note.unloaded = false
+
+	m.log().Debug("saving manager")
+
+	if err := m.Save(); err != nil {
+		m.log().Error("failed to save manager", "err", err)
+		return err
+	}
+
+	if err := m.files().Remove(encryptedPath); err != nil {
+		m.log().Error("failed to remove encrypted note file", "err", err)
+		return err
+	}
+
+	m.emitChange(EventUpdated, filename, &old, note)
+	return nil
+}
+
+// Open an encrypted note in the editor. The note is decrypted to a private
+// temporary file, which is encrypted again and removed once the editor exits
+func (m *Manager) openEncryptedNote(note *Note, line int) error {
+	m.log().Info("decrypting note for editing", "filename", note.Filename)
+
+	content, err := m.readEncrypted(note)
+	if err != nil {
+		m.log().Error("failed to decrypt note", "err", err)
+		return err
+	}
+
+	// Create a temporary file only readable by the user
+	tmp, err := os.CreateTemp("", note.Filename+"-*.md")
+	if err != nil {
+		m.log().Error("failed to create temporary file", "err", err)
+		return err
+	}
+	// The temporary file is on disk for the editor, whatever the manager's store
+	defer shredFile(OSStore{}, tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		m.log().Error("failed to write temporary file", "err", err)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		m.log().Error("failed to write temporary file", "err", err)
+		return err
+	}
+
+	// Open the temporary file in the editor
+	if err := m.runEditor(tmp.Name(), line); err != nil {
+		m.log().Error("failed to open note in editor", "err", err)
+		return err
+	}
+
+	m.log().Debug("note edited, encrypting it again")
+
+	content, err = os.ReadFile(tmp.Name())
+	if err != nil {
+		m.log().Error("failed to read temporary file", "err", err)
+		return err
+	}
+
+	if err := m.writeEncrypted(note, content); err != nil {
+		m.log().Error("failed to encrypt note", "err", err)
+		return err
+	}
+
+	note.UpdatedAt = m.now()
+
+	m.log().Debug("saving manager")
+
+	// Save the manager to storage
+	if err := m.Save(); err != nil {
+		m.log().Error("failed to save manager", "err", err)
+		return err
+	}
+
+	return nil
+}
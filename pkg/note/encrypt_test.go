@@ -0,0 +1,198 @@
+package note_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper function to return a passphrase source for tests
+func testPassphrase(passphrase string) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		return []byte(passphrase), nil
+	}
+}
+
+// Test encrypting and decrypting a note
+func TestEncryptDecryptNote(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	manager.Passphrase = testPassphrase("correct horse")
+
+	require.Nil(manager.CreateNote("secret"))
+	require.Nil(manager.UpdateNote("secret", "# Secret\n\nhidden words\n"))
+
+	// Encrypt the note, which replaces the plaintext file
+	require.Nil(manager.EncryptNote("secret"))
+	require.True(manager.GetNote("secret").Encrypted)
+	require.Equal("", manager.GetNote("secret").Content)
+
+	_, err = os.Stat("./testing/dirty/entries/secret.md")
+	require.True(os.IsNotExist(err))
+
+	file, err := os.ReadFile("./testing/dirty/entries/secret.md.enc")
+	require.Nil(err)
+	require.True(strings.HasPrefix(string(file), "NOTE-ENCRYPTED v1\n"))
+	require.NotContains(string(file), "hidden words")
+
+	// Encrypting twice fails
+	require.NotNil(manager.EncryptNote("secret"))
+
+	// Encrypted content is not searchable
+	require.Empty(manager.SearchNotes("hidden"))
+
+	// Reloading the manager keeps the note encrypted
+	loaded, err := note.GetManager()
+	require.Nil(err)
+	require.True(loaded.GetNote("secret").Encrypted)
+
+	// Decrypt the note
+	require.Nil(manager.DecryptNote("secret"))
+	require.False(manager.GetNote("secret").Encrypted)
+
+	content, err := os.ReadFile("./testing/dirty/entries/secret.md")
+	require.Nil(err)
+	require.Equal("# Secret\n\nhidden words\n", string(content))
+
+	_, err = os.Stat("./testing/dirty/entries/secret.md.enc")
+	require.True(os.IsNotExist(err))
+}
+
+// Store that fails to write one file
+type failingStore struct {
+	*note.MemoryStore
+	fail string // Path of the file that can't be written
+}
+
+func (s *failingStore) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if name == s.fail {
+		return errors.New("disk full")
+	}
+	return s.MemoryStore.WriteFile(name, data, perm)
+}
+
+// Test encrypting a note in a store other than the filesystem
+func TestEncryptNoteStore(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	store := &failingStore{MemoryStore: &note.MemoryStore{}}
+	manager, err := note.New(
+		note.WithConfigPath("/vault/config.json"),
+		note.WithManagerPath("/vault/manager.json"),
+		note.WithDirectory("/vault/entries"),
+		note.WithStore(store),
+	)
+	require.Nil(err)
+	manager.Passphrase = testPassphrase("correct horse")
+
+	require.Nil(manager.CreateNote("secret"))
+	require.Nil(manager.UpdateNote("secret", "# Secret\n\nhidden words\n"))
+
+	// A failed save keeps the note as plaintext
+	store.fail = "/vault/manager.json"
+	require.NotNil(manager.EncryptNote("secret"))
+	require.False(manager.GetNote("secret").Encrypted)
+	require.Equal("# Secret\n\nhidden words\n", manager.GetNote("secret").Content)
+
+	_, err = store.Stat("/vault/entries/secret.md")
+	require.Nil(err)
+	_, err = store.Stat("/vault/entries/secret.md.enc")
+	require.True(os.IsNotExist(err))
+
+	// The plaintext file is removed from the store
+	store.fail = ""
+	require.Nil(manager.EncryptNote("secret"))
+
+	_, err = store.Stat("/vault/entries/secret.md")
+	require.True(os.IsNotExist(err))
+
+	file, err := store.ReadFile("/vault/entries/secret.md.enc")
+	require.Nil(err)
+	require.NotContains(string(file), "hidden words")
+}
+
+// Test decrypting a note with the wrong passphrase
+func TestDecryptNoteWrongPassphrase(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	manager.Passphrase = testPassphrase("correct horse")
+
+	require.Nil(manager.CreateNote("secret"))
+	require.Nil(manager.EncryptNote("secret"))
+
+	// The wrong passphrase is rejected and the note stays encrypted
+	manager.Passphrase = testPassphrase("battery staple")
+	require.NotNil(manager.DecryptNote("secret"))
+	require.True(manager.GetNote("secret").Encrypted)
+
+	// No passphrase at all is also rejected
+	manager.Passphrase = nil
+	require.NotNil(manager.DecryptNote("secret"))
+	require.NotNil(manager.OpenNote("secret"))
+}
+
+// Test opening an encrypted note in the editor
+func TestOpenEncryptedNote(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	manager.Passphrase = testPassphrase("correct horse")
+
+	require.Nil(manager.CreateNote("secret"))
+	require.Nil(manager.EncryptNote("secret"))
+
+	before, err := os.ReadFile("./testing/dirty/entries/secret.md.enc")
+	require.Nil(err)
+
+	// Opening the note decrypts it for the editor and encrypts it again
+	require.Nil(manager.OpenNote("secret"))
+
+	after, err := os.ReadFile("./testing/dirty/entries/secret.md.enc")
+	require.Nil(err)
+	require.NotEqual(string(before), string(after))
+
+	_, err = os.Stat("./testing/dirty/entries/secret.md")
+	require.True(os.IsNotExist(err))
+
+	// Encrypted notes cannot be published
+	_, err = manager.PublishNote("secret", "./testing/dirty/published")
+	require.NotNil(err)
+}
+
+// Test backing up and restoring encrypted notes
+func TestBackupRestoreEncrypted(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	manager.Passphrase = testPassphrase("correct horse")
+
+	require.Nil(manager.CreateNote("secret"))
+	require.Nil(manager.UpdateNote("secret", "hidden words\n"))
+	require.Nil(manager.EncryptNote("secret"))
+
+	// Back up the notebook, which keeps the note encrypted in the archive
+	archive := path.Join("testing/dirty", "backup.tar.gz")
+	_, err = manager.Backup(archive)
+	require.Nil(err)
+
+	// Restore the archive after deleting the note
+	require.Nil(manager.DeleteNote("secret"))
+	_, err = manager.Restore(archive, note.RestoreReplace)
+	require.Nil(err)
+
+	require.True(manager.GetNote("secret").Encrypted)
+	require.Nil(manager.DecryptNote("secret"))
+	require.Equal("hidden words\n", manager.GetNote("secret").Content)
+}
@@ -0,0 +1,55 @@
+package note
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned by the package, wrapped with the name of the note, vault, or
+// key involved. Use errors.Is to check for them
+var (
+	ErrNotFound      = errors.New("not found")            // A note or vault does not exist
+	ErrDuplicate     = errors.New("duplicate")            // A note or vault with the same name already exists
+	ErrInvalidName   = errors.New("invalid name")         // A note or vault name contains invalid characters
+	ErrAmbiguous     = errors.New("ambiguous")            // A query matches more than one note
+	ErrEncrypted     = errors.New("encrypted")            // An operation isn't possible on an encrypted note, or the note isn't encrypted
+	ErrPassphrase    = errors.New("incorrect passphrase") // A passphrase is missing or doesn't decrypt a note
+	ErrCorrupted     = errors.New("corrupted")            // An encrypted note or backup archive is corrupted
+	ErrInvalidConfig = errors.New("invalid config")       // A configuration key or value is invalid
+	ErrEditorFailed  = errors.New("editor failed")        // The editor could not be started or exited unsuccessfully
+	ErrHookFailed    = errors.New("hook failed")          // A hook exited unsuccessfully, aborting the operation
+	ErrInvalidField  = errors.New("invalid field")        // A note's custom fields don't match the declared fields
+	ErrInvalidQuery  = errors.New("invalid query")        // A query can't be parsed
+)
+
+// wrappedError is an error with its own message that matches a sentinel error
+// with errors.Is
+type wrappedError struct {
+	msg string
+	err error
+}
+
+func (e *wrappedError) Error() string { return e.msg }
+func (e *wrappedError) Unwrap() error { return e.err }
+
+// Return an error with a formatted message that wraps the provided error
+func wrapf(err error, format string, v ...any) error {
+	return &wrappedError{msg: fmt.Sprintf(format, v...), err: err}
+}
+
+// EditorError is returned when the editor could not be started or exited
+// unsuccessfully. It matches ErrEditorFailed and unwraps to the error
+// returned by the editor's process
+type EditorError struct {
+	Command []string // Command used to run the editor
+	Err     error    // Error returned when running the editor
+}
+
+func (e *EditorError) Error() string {
+	return fmt.Sprintf("editor '%s' failed (err: %v)", strings.Join(e.Command, " "), e.Err)
+}
+
+func (e *EditorError) Unwrap() error { return e.Err }
+
+func (e *EditorError) Is(target error) bool { return target == ErrEditorFailed }
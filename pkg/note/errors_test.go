@@ -0,0 +1,66 @@
+package note_test
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that errors match their sentinel errors and keep their messages
+func TestSentinelErrors(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	require.Nil(manager.CreateNote("note-1"))
+	require.Nil(manager.CreateNote("note-2"))
+
+	err = manager.DeleteNote("missing")
+	require.True(errors.Is(err, note.ErrNotFound))
+	require.Equal("note with name 'missing' not found", err.Error())
+
+	err = manager.CreateNote("note-1")
+	require.True(errors.Is(err, note.ErrDuplicate))
+	require.Equal("duplicate note name 'note-1'", err.Error())
+
+	err = manager.CreateNote("$note")
+	require.True(errors.Is(err, note.ErrInvalidName))
+	require.Equal("invalid name '$note'", err.Error())
+
+	_, err = manager.ResolveNote("note")
+	require.True(errors.Is(err, note.ErrAmbiguous))
+	require.False(errors.Is(err, note.ErrNotFound))
+
+	err = manager.DecryptNote("note-1")
+	require.True(errors.Is(err, note.ErrEncrypted))
+
+	err = manager.EncryptNote("note-1")
+	require.True(errors.Is(err, note.ErrPassphrase))
+
+	err = manager.Config.Set("backup_keep", "-1")
+	require.True(errors.Is(err, note.ErrInvalidConfig))
+}
+
+// Test that editor failures match ErrEditorFailed and keep the process error
+func TestEditorFailed(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	require.Nil(manager.CreateNote("note-1"))
+
+	manager.Config.Editor = "false"
+	err = manager.OpenNote("note-1")
+	require.True(errors.Is(err, note.ErrEditorFailed))
+
+	editorErr := &note.EditorError{}
+	require.True(errors.As(err, &editorErr))
+	require.Equal([]string{"false", manager.NotePath("note-1")}, editorErr.Command)
+
+	exitErr := &exec.ExitError{}
+	require.True(errors.As(err, &exitErr))
+	require.Equal(1, exitErr.ExitCode())
+}
@@ -0,0 +1,132 @@
+package note
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// EventType is the kind of change made to a note
+type EventType string
+
+const (
+	EventCreated EventType = "created" // A note was created
+	EventUpdated EventType = "updated" // A note's content or metadata was changed
+	EventDeleted EventType = "deleted" // A note was deleted
+	EventRenamed EventType = "renamed" // A note's file was renamed outside the manager
+)
+
+// Event describes a change the manager made to a note
+type Event struct {
+	Type     EventType // Kind of change
+	Filename string    // Filename of the note that changed, which is the new filename of renamed notes
+	Old      *Metadata // Metadata before the change (nil for created notes)
+	New      *Metadata // Metadata after the change (nil for deleted notes)
+}
+
+// Subscribers to a manager's events. The zero value has no subscribers
+type eventBus struct {
+	mu       sync.Mutex
+	next     int
+	handlers map[int]func(Event)
+}
+
+// OnChange subscribes a function to the changes the manager makes to notes,
+// including notes changed by a restore or sync. Functions are called
+// synchronously, in the order they subscribed, after the change is saved. The
+// returned function unsubscribes
+func (m *Manager) OnChange(fn func(Event)) (unsubscribe func()) {
+	m.events.mu.Lock()
+	defer m.events.mu.Unlock()
+
+	if m.events.handlers == nil {
+		m.events.handlers = map[int]func(Event){}
+	}
+
+	id := m.events.next
+	m.events.next++
+	m.events.handlers[id] = fn
+
+	return func() {
+		m.events.mu.Lock()
+		defer m.events.mu.Unlock()
+		delete(m.events.handlers, id)
+	}
+}
+
+// Send an event to every subscriber
+func (m *Manager) emit(event Event) {
+	m.events.mu.Lock()
+	ids := []int{}
+	for id := range m.events.handlers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	handlers := []func(Event){}
+	for _, id := range ids {
+		handlers = append(handlers, m.events.handlers[id])
+	}
+	m.events.mu.Unlock()
+
+	m.log().Debug("emitting event", "type", event.Type, "filename", event.Filename, "subscribers", len(handlers))
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// Send an event for a change to a note, given its metadata before the change
+func (m *Manager) emitChange(eventType EventType, filename string, old *Metadata, note *Note) {
+	event := Event{Type: eventType, Filename: filename, Old: old}
+	if note != nil {
+		current := note.Metadata
+		event.New = &current
+	}
+
+	m.emit(event)
+}
+
+// Return the metadata of every note, keyed by filename
+func (m *Manager) snapshot() map[string]Metadata {
+	notes := map[string]Metadata{}
+	for _, note := range m.Notes {
+		notes[note.Filename] = note.Metadata
+	}
+
+	return notes
+}
+
+// Send events for the notes that were created, updated, or deleted since a
+// snapshot was taken, for changes made to many notes at once
+func (m *Manager) emitSince(before map[string]Metadata) {
+	for _, note := range m.Notes {
+		old, ok := before[note.Filename]
+		if !ok {
+			m.emitChange(EventCreated, note.Filename, nil, note)
+		} else if !sameMetadata(old, note.Metadata) {
+			m.emitChange(EventUpdated, note.Filename, &old, note)
+		}
+	}
+
+	filenames := []string{}
+	for filename := range before {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		if ok, _ := m.contains(filename); !ok {
+			old := before[filename]
+			m.emitChange(EventDeleted, filename, &old, nil)
+		}
+	}
+}
+
+// Return whether two versions of metadata are the same, comparing times by the
+// instant they represent
+func sameMetadata(a Metadata, b Metadata) bool {
+	return a.Filename == b.Filename && a.Title == b.Title && a.Author == b.Author &&
+		a.CreatedAt.Equal(b.CreatedAt) && a.UpdatedAt.Equal(b.UpdatedAt) &&
+		a.Encrypted == b.Encrypted && reflect.DeepEqual(a.Fields, b.Fields)
+}
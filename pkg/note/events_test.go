@@ -0,0 +1,79 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that subscribers receive events with the metadata before and after each change
+func TestOnChange(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+
+	events := []note.Event{}
+	unsubscribe := manager.OnChange(func(e note.Event) {
+		events = append(events, e)
+	})
+
+	require.Nil(manager.CreateNote("note-1"))
+	require.Nil(manager.UpdateNote("note-1", "updated"))
+	require.Nil(manager.OpenNote("note-1"))
+	require.Nil(manager.DeleteNote("note-1"))
+
+	require.Len(events, 4)
+
+	require.Equal(note.EventCreated, events[0].Type)
+	require.Equal("note-1", events[0].Filename)
+	require.Nil(events[0].Old)
+	require.Equal("note-1", events[0].New.Filename)
+
+	require.Equal(note.EventUpdated, events[1].Type)
+	require.Equal(events[0].New.UpdatedAt, events[1].Old.UpdatedAt)
+	require.False(events[1].New.UpdatedAt.Before(events[1].Old.UpdatedAt))
+
+	require.Equal(note.EventUpdated, events[2].Type)
+	require.Equal(*events[1].New, *events[2].Old)
+
+	require.Equal(note.EventDeleted, events[3].Type)
+	require.Equal(*events[2].New, *events[3].Old)
+	require.Nil(events[3].New)
+
+	// Failed changes send no events, and unsubscribed functions are not called
+	require.NotNil(manager.DeleteNote("note-1"))
+	unsubscribe()
+	require.Nil(manager.CreateNote("note-2"))
+	require.Len(events, 4)
+}
+
+// Test that restores send an event for every note they change
+func TestOnChangeRestore(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	require.Nil(manager.CreateNote("note-1"))
+	require.Nil(manager.CreateNote("note-2"))
+
+	archive := t.TempDir() + "/backup.tar.gz"
+	_, err = manager.Backup(archive)
+	require.Nil(err)
+
+	require.Nil(manager.DeleteNote("note-1"))
+	require.Nil(manager.CreateNote("note-3"))
+
+	events := map[string]note.EventType{}
+	manager.OnChange(func(e note.Event) {
+		events[e.Filename] = e.Type
+	})
+
+	_, err = manager.Restore(archive, note.RestoreReplace)
+	require.Nil(err)
+	require.Equal(map[string]note.EventType{
+		"note-1": note.EventCreated,
+		"note-3": note.EventDeleted,
+	}, events)
+}
@@ -0,0 +1,166 @@
+package note
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Exporter converts notes into a format they can be published in
+type Exporter struct {
+	Name      string                           // Name the format is selected by, such as 'html'
+	Extension string                           // Extension of published files, including the dot
+	Export    func(note *Note) ([]byte, error) // Convert a note into the format
+}
+
+// FrontMatterField is an extra field written to the front matter of published
+// markdown notes
+type FrontMatterField struct {
+	Name  string               // Key of the field in the front matter
+	Value func(note *Note) any // Value of the field for a note, omitted when nil
+}
+
+// Registered exporters and front matter fields
+var (
+	registryMu        sync.RWMutex
+	exporters         = map[string]Exporter{}
+	frontMatterFields = []FrontMatterField{}
+)
+
+// Name of the format notes are published in by default
+const DefaultExportFormat = "markdown"
+
+func init() {
+	RegisterExporter(Exporter{
+		Name:      "markdown",
+		Extension: ".md",
+		Export: func(note *Note) ([]byte, error) {
+			return []byte(note.AsMarkdown()), nil
+		},
+	})
+	RegisterExporter(Exporter{
+		Name:      "html",
+		Extension: ".html",
+		Export: func(note *Note) ([]byte, error) {
+			output := note.AsHTML()
+			if output == "" {
+				return nil, fmt.Errorf("failed to render note '%s' as HTML", note.Filename)
+			}
+			return []byte(output), nil
+		},
+	})
+}
+
+// RegisterExporter adds a format notes can be published in. An error is
+// returned if the name is empty or already registered
+func RegisterExporter(exporter Exporter) error {
+	if exporter.Name == "" || exporter.Export == nil {
+		return fmt.Errorf("exporter must have a name and an export function")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := exporters[exporter.Name]; ok {
+		return wrapf(ErrDuplicate, "duplicate exporter '%s'", exporter.Name)
+	}
+	exporters[exporter.Name] = exporter
+
+	return nil
+}
+
+// LookupExporter returns the exporter registered with the provided name
+func LookupExporter(name string) (Exporter, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	exporter, ok := exporters[name]
+	if !ok {
+		return Exporter{}, wrapf(ErrNotFound, "exporter '%s' not found", name)
+	}
+
+	return exporter, nil
+}
+
+// Exporters returns every registered exporter, sorted by name
+func Exporters() []Exporter {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	list := []Exporter{}
+	for _, exporter := range exporters {
+		list = append(list, exporter)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+// RegisterFrontMatterField adds a field to the front matter of published
+// markdown notes, after the built-in fields. An error is returned if the name
+// is empty or already used
+func RegisterFrontMatterField(field FrontMatterField) error {
+	if field.Name == "" || field.Value == nil {
+		return fmt.Errorf("front matter field must have a name and a value function")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, name := range append([]string{"title", "author", "createdAt", "updatedAt"}, fieldNames(frontMatterFields)...) {
+		if name == field.Name {
+			return wrapf(ErrDuplicate, "duplicate front matter field '%s'", field.Name)
+		}
+	}
+	frontMatterFields = append(frontMatterFields, field)
+
+	return nil
+}
+
+// Return the names of front matter fields
+func fieldNames(fields []FrontMatterField) []string {
+	names := []string{}
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+
+	return names
+}
+
+// Return the yaml lines of the registered front matter fields for a note
+func extraFrontMatter(note *Note) string {
+	registryMu.RLock()
+	fields := append([]FrontMatterField{}, frontMatterFields...)
+	registryMu.RUnlock()
+
+	output := strings.Builder{}
+	for _, field := range fields {
+		// Custom fields of the note take precedence
+		if _, ok := note.Fields[field.Name]; ok {
+			continue
+		}
+
+		value := field.Value(note)
+		if value == nil {
+			continue
+		}
+
+		output.WriteString(yamlLine(field.Name, value))
+	}
+
+	return output.String()
+}
+
+// Return a yaml line setting a key to a value, quoting the value if needed.
+// Values that can't be written as yaml are omitted
+func yamlLine(key string, value any) string {
+	line, err := yaml.Marshal(map[string]any{key: value})
+	if err != nil {
+		return ""
+	}
+
+	return string(line)
+}
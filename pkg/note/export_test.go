@@ -0,0 +1,74 @@
+package note_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test publishing notes with built-in and registered exporters
+func TestPublishNoteAs(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	require.Nil(manager.CreateNote("note-1"))
+
+	directory := t.TempDir()
+	filepath, err := manager.PublishNoteAs("note-1", directory, "html")
+	require.Nil(err)
+	require.Equal(directory+"/note-1.html", filepath)
+
+	content, err := os.ReadFile(filepath)
+	require.Nil(err)
+	require.Contains(string(content), "<h1>Note 1</h1>")
+
+	require.Nil(note.RegisterExporter(note.Exporter{
+		Name:      "test-upper",
+		Extension: ".txt",
+		Export: func(n *note.Note) ([]byte, error) {
+			return []byte(strings.ToUpper(n.Content)), nil
+		},
+	}))
+
+	filepath, err = manager.PublishNoteAs("note-1", directory, "test-upper")
+	require.Nil(err)
+	content, err = os.ReadFile(filepath)
+	require.Nil(err)
+	require.Equal("# NOTE 1\n\n", string(content))
+
+	err = note.RegisterExporter(note.Exporter{Name: "test-upper", Export: func(n *note.Note) ([]byte, error) { return nil, nil }})
+	require.True(errors.Is(err, note.ErrDuplicate))
+
+	_, err = manager.PublishNoteAs("note-1", directory, "pdf")
+	require.True(errors.Is(err, note.ErrNotFound))
+}
+
+// Test that registered front matter fields are added to markdown notes
+func TestRegisterFrontMatterField(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+
+	require.Nil(note.RegisterFrontMatterField(note.FrontMatterField{
+		Name: "words",
+		Value: func(n *note.Note) any {
+			// Only add the field to this test's note
+			if n.Filename != "front-matter" {
+				return nil
+			}
+			return len(strings.Fields(n.Content))
+		},
+	}))
+
+	n := &note.Note{Metadata: note.Metadata{Filename: "front-matter"}, Content: "one two three"}
+	metadata, _, err := note.ParseFrontMatter(n.AsMarkdown())
+	require.Nil(err)
+	require.Equal(3, metadata["words"])
+
+	err = note.RegisterFrontMatterField(note.FrontMatterField{Name: "author", Value: func(n *note.Note) any { return "" }})
+	require.True(errors.Is(err, note.ErrDuplicate))
+}
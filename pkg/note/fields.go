@@ -0,0 +1,402 @@
+package note
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the type of value a custom note field holds
+type FieldType string
+
+const (
+	TypeString FieldType = "string" // Any text
+	TypeNumber FieldType = "number" // An integer or decimal number
+	TypeBool   FieldType = "bool"   // true or false
+	TypeDate   FieldType = "date"   // A date written as YYYY-MM-DD
+	TypeList   FieldType = "list"   // A list of text values
+)
+
+// FieldTypes lists every field type
+var FieldTypes = []FieldType{TypeString, TypeNumber, TypeBool, TypeDate, TypeList}
+
+// Layout of date field values
+const dateLayout = "2006-01-02"
+
+// NoteField declares a custom field of notes, such as 'status' or 'due'.
+// Fields that aren't declared can still be set and hold any type of value
+type NoteField struct {
+	Type        FieldType `json:"type"`                  // Type of value the field holds
+	Description string    `json:"description,omitempty"` // Short description of the field
+	Default     any       `json:"default,omitempty"`     // Value given to new notes
+	Values      []string  `json:"values,omitempty"`      // Allowed values of string and list fields (empty allows any value)
+	Required    bool      `json:"required,omitempty"`    // Whether every note must set the field
+}
+
+// Names of metadata and values read from content that custom fields can't use
+var reservedFields = []string{
+	"filename", "title", "author", "createdAt", "created", "updatedAt", "updated", "encrypted",
+	"links", "backlinks", "words", "size", "content", "text",
+}
+
+// Regex to verify custom field names
+var fieldNameMatcher = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Check that a custom field name is valid and not reserved
+func validFieldName(name string) error {
+	if !fieldNameMatcher.MatchString(name) || slices.Contains(reservedFields, name) {
+		return wrapf(ErrInvalidName, "invalid field name '%s'", name)
+	}
+
+	return nil
+}
+
+// Return a copy of a map of note fields
+func copyNoteFields(fields map[string]NoteField) map[string]NoteField {
+	if fields == nil {
+		return nil
+	}
+
+	copied := map[string]NoteField{}
+	for name, field := range fields {
+		field.Values = append([]string(nil), field.Values...)
+		copied[name] = field
+	}
+
+	return copied
+}
+
+// Return a value as it is stored in a note's fields, so values read from
+// JSON, YAML, and Go compare equal. Numbers become float64, times become
+// dates, and lists become []any. The second result is false for values that
+// can't be stored
+func normalizeFieldValue(value any) (any, bool) {
+	switch v := value.(type) {
+	case string, bool, float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case time.Time:
+		if v.Equal(v.Truncate(24 * time.Hour)) {
+			return v.Format(dateLayout), true
+		}
+		return v.Format(time.RFC3339), true
+	case []string:
+		list := []any{}
+		for _, item := range v {
+			list = append(list, item)
+		}
+		return list, true
+	case []any:
+		list := []any{}
+		for _, item := range v {
+			normalized, ok := normalizeFieldValue(item)
+			if !ok {
+				return nil, false
+			}
+			if _, nested := normalized.([]any); nested {
+				return nil, false
+			}
+			list = append(list, normalized)
+		}
+		return list, true
+	default:
+		return nil, false
+	}
+}
+
+// Return the default values of the declared fields, or nil if no field has one
+func (c *Config) defaultFields() map[string]any {
+	var fields map[string]any
+	for name, field := range c.NoteFields {
+		if field.Default == nil {
+			continue
+		}
+
+		value, ok := normalizeFieldValue(field.Default)
+		if !ok {
+			continue
+		}
+
+		if fields == nil {
+			fields = map[string]any{}
+		}
+		fields[name] = value
+	}
+
+	return fields
+}
+
+// ParseFieldValue parses text given for a custom field, such as on the command
+// line, into a value of the field's declared type. Fields that aren't declared
+// hold numbers and booleans if the text is one, and text otherwise
+func (c *Config) ParseFieldValue(name string, text string) (any, error) {
+	field, declared := c.NoteFields[name]
+	if !declared {
+		if number, err := strconv.ParseFloat(text, 64); err == nil && !math.IsInf(number, 0) && !math.IsNaN(number) {
+			return number, nil
+		}
+		if text == "true" || text == "false" {
+			return text == "true", nil
+		}
+		return text, nil
+	}
+
+	switch field.Type {
+	case TypeNumber:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsInf(number, 0) || math.IsNaN(number) {
+			return nil, wrapf(ErrInvalidField, "field '%s' must be a number, not '%s'", name, text)
+		}
+		return number, nil
+	case TypeBool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, wrapf(ErrInvalidField, "field '%s' must be true or false, not '%s'", name, text)
+		}
+		return b, nil
+	case TypeList:
+		list := []any{}
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+	default:
+		return text, nil
+	}
+}
+
+// Check a value of a declared field against its type and allowed values
+func (f NoteField) validate(name string, value any) error {
+	switch f.Type {
+	case TypeString:
+		if _, ok := value.(string); !ok {
+			return wrapf(ErrInvalidField, "field '%s' must be text", name)
+		}
+	case TypeNumber:
+		if _, ok := value.(float64); !ok {
+			return wrapf(ErrInvalidField, "field '%s' must be a number", name)
+		}
+	case TypeBool:
+		if _, ok := value.(bool); !ok {
+			return wrapf(ErrInvalidField, "field '%s' must be true or false", name)
+		}
+	case TypeDate:
+		text, ok := value.(string)
+		if _, err := time.Parse(dateLayout, text); !ok || err != nil {
+			return wrapf(ErrInvalidField, "field '%s' must be a date written as YYYY-MM-DD", name)
+		}
+	case TypeList:
+		if _, ok := value.([]any); !ok {
+			return wrapf(ErrInvalidField, "field '%s' must be a list", name)
+		}
+	}
+
+	if len(f.Values) == 0 {
+		return nil
+	}
+
+	values := []any{value}
+	if list, ok := value.([]any); ok {
+		values = list
+	}
+	for _, v := range values {
+		if text, ok := v.(string); !ok || !slices.Contains(f.Values, text) {
+			return wrapf(ErrInvalidField, "invalid value '%v' for field '%s' (allowed: %s)", v, name, strings.Join(f.Values, ", "))
+		}
+	}
+
+	return nil
+}
+
+// ValidateFields checks a note's custom fields against the declared fields,
+// returning an error for missing required fields and for values of the wrong
+// type or that aren't allowed. Fields that aren't declared are not checked
+func (c *Config) ValidateFields(fields map[string]any) error {
+	names := []string{}
+	for name := range c.NoteFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := c.NoteFields[name]
+		value, ok := fields[name]
+		if !ok {
+			if field.Required {
+				return wrapf(ErrInvalidField, "missing required field '%s'", name)
+			}
+			continue
+		}
+
+		if err := field.validate(name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Check that the declared fields have valid names, types, and defaults
+func (c *Config) validateNoteFields() error {
+	for name, field := range c.NoteFields {
+		if err := validFieldName(name); err != nil {
+			return wrapf(ErrInvalidConfig, "invalid field name '%s'", name)
+		}
+
+		if !slices.Contains(FieldTypes, field.Type) {
+			return wrapf(ErrInvalidConfig, "invalid type '%s' for field '%s'", field.Type, name)
+		}
+
+		if field.Default == nil {
+			continue
+		}
+
+		value, ok := normalizeFieldValue(field.Default)
+		if !ok {
+			return wrapf(ErrInvalidConfig, "invalid default for field '%s'", name)
+		}
+		if err := field.validate(name, value); err != nil {
+			return wrapf(ErrInvalidConfig, "invalid default for field '%s' (%v)", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Set the custom fields of a note from the front matter of its content, if it
+// has any. Keys in the front matter replace fields of the same name, while
+// other fields, such as those set with SetFields, are kept. Metadata keys, such
+// as title and author, are left out
+func (a *Note) syncFields() {
+	frontMatter, _, err := ParseFrontMatter(a.Content)
+	if err != nil || frontMatter == nil {
+		return
+	}
+
+	// Fields are copied rather than changed in place, so copies of the
+	// note's metadata keep their values
+	fields := map[string]any{}
+	for name, value := range a.Fields {
+		fields[name] = value
+	}
+
+	for name, value := range frontMatter {
+		if validFieldName(name) != nil {
+			continue
+		}
+		if normalized, ok := normalizeFieldValue(value); ok {
+			fields[name] = normalized
+		}
+	}
+
+	if len(fields) == 0 {
+		fields = nil
+	}
+	a.Fields = fields
+}
+
+// Return the front matter lines of the note's custom fields, sorted by name
+func (a *Note) fieldsFrontMatter() string {
+	names := []string{}
+	for name := range a.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	output := strings.Builder{}
+	for _, name := range names {
+		output.WriteString(yamlLine(name, a.Fields[name]))
+	}
+
+	return output.String()
+}
+
+// Check the custom fields of an edited note against the declared fields. The
+// edit is already saved, so the error only reports fields that need fixing
+func (m *Manager) checkFields(note *Note) error {
+	if err := m.Config.ValidateFields(note.Fields); err != nil {
+		m.log().Warn("note fields are invalid", "filename", note.Filename, "err", err)
+		return wrapf(ErrInvalidField, "note '%s' was saved with invalid fields (%v)", note.Filename, err)
+	}
+
+	return nil
+}
+
+// SetFields sets custom fields of an existing note, removing fields whose
+// value is nil. Values are checked against the declared fields, but missing
+// required fields are not, so they can be set one at a time
+func (m *Manager) SetFields(filename string, values map[string]any) error {
+	m.log().Info("setting note fields", "filename", filename, "fields", len(values))
+
+	filename = strings.ToLower(filename)
+
+	// Make sure the filename exists in the manager
+	index, ok := -1, false
+	if ok, index = m.contains(filename); !ok {
+		m.log().Error("note not found", "filename", filename)
+		return wrapf(ErrNotFound, "note with name '%s' not found", filename)
+	}
+
+	note := m.Notes[index]
+
+	// Fields are copied rather than changed in place, so copies of the
+	// note's metadata keep their values
+	fields := map[string]any{}
+	for name, value := range note.Fields {
+		fields[name] = value
+	}
+
+	for name, value := range values {
+		if err := validFieldName(name); err != nil {
+			return err
+		}
+
+		if value == nil {
+			delete(fields, name)
+			continue
+		}
+
+		normalized, ok := normalizeFieldValue(value)
+		if !ok {
+			return wrapf(ErrInvalidField, "unsupported value for field '%s'", name)
+		}
+		if field, declared := m.Config.NoteFields[name]; declared {
+			if err := field.validate(name, normalized); err != nil {
+				return err
+			}
+		}
+		fields[name] = normalized
+	}
+
+	if len(fields) == 0 {
+		fields = nil
+	}
+
+	old := note.Metadata
+	note.Fields = fields
+	note.UpdatedAt = m.now()
+
+	m.log().Debug("saving manager")
+
+	// Save the manager to storage
+	if err := m.Save(); err != nil {
+		m.log().Error("failed to save manager", "err", err)
+		return err
+	}
+
+	m.emitChange(EventUpdated, filename, &old, note)
+	return nil
+}
@@ -0,0 +1,205 @@
+package note_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Return a manager backed by memory with a schema of custom fields
+func fieldsTestSetup(require *require.Assertions) (*note.Manager, *note.MemoryStore) {
+	store := &note.MemoryStore{}
+	manager, err := note.New(
+		note.WithConfigPath("/vault/config.json"),
+		note.WithManagerPath("/vault/manager.json"),
+		note.WithDirectory("/vault/entries"),
+		note.WithStore(store),
+	)
+	require.Nil(err)
+
+	manager.Config.Editor = "true"
+	manager.Config.NoteFields = map[string]note.NoteField{
+		"status":    {Type: note.TypeString, Default: "draft", Values: []string{"draft", "review", "done"}, Required: true},
+		"priority":  {Type: note.TypeNumber},
+		"due":       {Type: note.TypeDate},
+		"reviewers": {Type: note.TypeList},
+	}
+	require.Nil(manager.Config.Validate())
+	require.Nil(manager.Config.Save())
+
+	return manager, store
+}
+
+// Test setting custom fields and keeping them in the manager file
+func TestSetFields(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, store := fieldsTestSetup(require)
+
+	// New notes get the default values
+	require.Nil(manager.CreateNote("note-1"))
+	require.Equal(map[string]any{"status": "draft"}, manager.GetNote("note-1").Fields)
+
+	priority, err := manager.Config.ParseFieldValue("priority", "3")
+	require.Nil(err)
+	reviewers, err := manager.Config.ParseFieldValue("reviewers", "ana, bo")
+	require.Nil(err)
+	project, err := manager.Config.ParseFieldValue("project", "apollo")
+	require.Nil(err)
+
+	require.Nil(manager.SetFields("note-1", map[string]any{
+		"priority":  priority,
+		"reviewers": reviewers,
+		"project":   project,
+		"due":       "2024-06-01",
+	}))
+
+	// Fields round-trip through the manager file
+	manager, err = note.New(
+		note.WithConfigPath("/vault/config.json"),
+		note.WithManagerPath("/vault/manager.json"),
+		note.WithDirectory("/vault/entries"),
+		note.WithStore(store),
+	)
+	require.Nil(err)
+	require.Equal(map[string]any{
+		"status":    "draft",
+		"priority":  3.0,
+		"reviewers": []any{"ana", "bo"},
+		"project":   "apollo",
+		"due":       "2024-06-01",
+	}, manager.GetNote("note-1").Fields)
+
+	// Nil values remove fields
+	require.Nil(manager.SetFields("note-1", map[string]any{"project": nil}))
+	require.NotContains(manager.GetNote("note-1").Fields, "project")
+
+	_, err = manager.Config.ParseFieldValue("priority", "high")
+	require.True(errors.Is(err, note.ErrInvalidField))
+	require.True(errors.Is(manager.SetFields("note-1", map[string]any{"title": "x"}), note.ErrInvalidName))
+	require.True(errors.Is(manager.SetFields("missing", map[string]any{"a": "b"}), note.ErrNotFound))
+}
+
+// Test checking fields against the schema
+func TestValidateFields(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, _ := fieldsTestSetup(require)
+	config := manager.Config
+
+	require.Nil(config.ValidateFields(map[string]any{"status": "done", "priority": 1.0, "other": true}))
+
+	for _, fields := range []map[string]any{
+		{},
+		{"status": "archived"},
+		{"status": "done", "priority": "high"},
+		{"status": "done", "due": "tomorrow"},
+		{"status": "done", "reviewers": "ana"},
+	} {
+		require.True(errors.Is(config.ValidateFields(fields), note.ErrInvalidField), fields)
+	}
+
+	// The schema itself is validated with the config
+	config.NoteFields["size"] = note.NoteField{Type: "huge"}
+	require.True(errors.Is(config.Validate(), note.ErrInvalidConfig))
+	config.NoteFields["size"] = note.NoteField{Type: note.TypeNumber, Default: "big"}
+	require.True(errors.Is(config.Validate(), note.ErrInvalidConfig))
+	delete(config.NoteFields, "size")
+	config.NoteFields["author"] = note.NoteField{Type: note.TypeString}
+	require.True(errors.Is(config.Validate(), note.ErrInvalidConfig))
+}
+
+// Test setting fields from front matter when a note is edited
+func TestOpenNoteFrontMatter(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, store := fieldsTestSetup(require)
+	require.Nil(manager.CreateNote("note-1"))
+
+	content := "---\nstatus: review\npriority: 2\ndue: 2024-06-01\nreviewers: [ana, bo]\n---\n\n# Note 1\n"
+	require.Nil(store.WriteFile("/vault/entries/note-1.md", []byte(content), 0600))
+	require.Nil(manager.OpenNote("note-1"))
+
+	n := manager.GetNote("note-1")
+	require.Equal(map[string]any{
+		"status":    "review",
+		"priority":  2.0,
+		"due":       "2024-06-01",
+		"reviewers": []any{"ana", "bo"},
+	}, n.Fields)
+
+	// Published front matter holds the fields once, and round-trips
+	markdown := n.AsMarkdown()
+	require.Equal(1, strings.Count(markdown, "status: review"))
+	require.True(strings.HasSuffix(markdown, "---\n\n# Note 1\n"))
+
+	// Fields set outside of the front matter are kept when the note is edited
+	require.Nil(manager.SetFields("note-1", map[string]any{"project": "apollo"}))
+	require.Nil(manager.UpdateNote("note-1", "---\ntags: [a]\npriority: 3\n---\n\n# Note 1\n"))
+	require.Equal(map[string]any{
+		"status":    "review",
+		"priority":  3.0,
+		"due":       "2024-06-01",
+		"reviewers": []any{"ana", "bo"},
+		"project":   "apollo",
+		"tags":      []any{"a"},
+	}, manager.GetNote("note-1").Fields)
+
+	require.Nil(store.WriteFile("/vault/entries/note-1.md", []byte("# Note 1\n"), 0600))
+	require.Nil(manager.OpenNote("note-1"))
+	require.Equal("apollo", manager.GetNote("note-1").Fields["project"])
+
+	// Invalid fields are saved but reported
+	require.Nil(store.WriteFile("/vault/entries/note-1.md", []byte("---\nstatus: archived\n---\n"), 0600))
+	err := manager.OpenNote("note-1")
+	require.True(errors.Is(err, note.ErrInvalidField))
+	require.Equal("archived", manager.GetNote("note-1").Fields["status"])
+}
+
+// Test listing notes that match a where condition
+func TestListNotesWhere(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, _ := fieldsTestSetup(require)
+
+	for i, fields := range []map[string]any{
+		{"status": "draft", "priority": 3, "reviewers": []string{"ana"}},
+		{"status": "draft", "priority": 1, "due": "2024-06-01"},
+		{"status": "done", "priority": 5, "due": "2024-07-01", "reviewers": []string{"ana", "bo"}},
+		{"status": "review"},
+	} {
+		filename := []string{"alpha", "beta", "gamma", "delta"}[i]
+		require.Nil(manager.CreateNote(filename))
+		require.Nil(manager.SetFields(filename, fields))
+	}
+
+	list := func(where string) []string {
+		notes, err := manager.ListNotes(note.ListOptions{Where: where})
+		require.Nil(err, where)
+
+		filenames := []string{}
+		for _, n := range notes {
+			filenames = append(filenames, n.Filename)
+		}
+		return filenames
+	}
+
+	require.Equal([]string{"alpha"}, list("status=draft AND priority>2"))
+	require.Equal([]string{"alpha", "beta"}, list("status = 'draft'"))
+	require.Equal([]string{"gamma", "delta"}, list("status!=draft"))
+	require.Equal([]string{"beta", "gamma"}, list("due >= 2024-06-01"))
+	require.Equal([]string{"beta"}, list("due<2024-07-01 and priority<=1"))
+	require.Equal([]string{"alpha", "gamma"}, list("reviewers=ana"))
+	require.Equal([]string{"alpha", "beta", "delta"}, list("reviewers!=bo"))
+	require.Equal([]string{"delta"}, list("priority="))
+	require.Equal([]string{"alpha", "beta", "gamma", "delta"}, list("title~a"))
+	require.Equal([]string{"gamma"}, list("filename=Gamma"))
+
+	for _, where := range []string{"status", "status=draft AND", "9x=1", "title='open"} {
+		_, err := manager.ListNotes(note.ListOptions{Where: where})
+		require.True(errors.Is(err, note.ErrInvalidQuery), where)
+	}
+}
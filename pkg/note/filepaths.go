@@ -14,16 +14,24 @@ var configPath string
 // Path to the manager file where note metadata is stored
 var managerPath string
 
-// Get the user's home directory to concatenate with default paths
+// Path to the file where named vaults are registered
+var vaultsPath string
+
+// Path to the user's home directory
+var homePath string
+
+// Get the user's home directory to concatenate with default paths. Without a
+// home directory the default paths are left empty, and managers must be created
+// with explicit paths
 func init() {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		panic(err)
+		return
 	}
 
-	defaultDirectoryPath = path.Join(home, ".local/share/notes/entries/")
-	configPath = path.Join(home, ".config/note.json")
-	managerPath = path.Join(home, ".local/share/notes/manager.json")
+	homePath = home
+	defaultDirectoryPath, configPath, managerPath = DefaultVault.Paths()
+	vaultsPath = path.Join(home, ".config/note-vaults.json")
 }
 
 // Change the default directory path. This is an experimental function and
@@ -43,3 +51,9 @@ func ModifyConfigPath(path string) {
 func ModifyManagerPath(path string) {
 	managerPath = path
 }
+
+// Modify the path of the vault registry. This is an experimental function and
+// should be avoided unless absolutely necessary
+func ModifyVaultsPath(path string) {
+	vaultsPath = path
+}
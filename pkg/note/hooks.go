@@ -0,0 +1,140 @@
+package note
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HookEvent is a point in a note's lifecycle where a hook can run
+type HookEvent string
+
+const (
+	HookPreCreate   HookEvent = "pre_create"   // Before a note is created
+	HookPostCreate  HookEvent = "post_create"  // After a note is created
+	HookPreEdit     HookEvent = "pre_edit"     // Before a note is opened in the editor
+	HookPostEdit    HookEvent = "post_edit"    // After a note is edited in the editor
+	HookPreDelete   HookEvent = "pre_delete"   // Before a note is deleted
+	HookPostDelete  HookEvent = "post_delete"  // After a note is deleted
+	HookPrePublish  HookEvent = "pre_publish"  // Before a note is published
+	HookPostPublish HookEvent = "post_publish" // After a note is published
+)
+
+// Every hook event, in the order they are listed
+var HookEvents = []HookEvent{
+	HookPreCreate, HookPostCreate,
+	HookPreEdit, HookPostEdit,
+	HookPreDelete, HookPostDelete,
+	HookPrePublish, HookPostPublish,
+}
+
+// HookCommand is a command run by a hook. In the config file it is either an
+// array of arguments or a string that is split like a shell command
+type HookCommand []string
+
+func (h *HookCommand) UnmarshalJSON(data []byte) error {
+	command := ""
+	if err := json.Unmarshal(data, &command); err == nil {
+		words, err := SplitCommand(command)
+		if err != nil {
+			return err
+		}
+
+		*h = words
+		return nil
+	}
+
+	words := []string{}
+	if err := json.Unmarshal(data, &words); err != nil {
+		return fmt.Errorf("hook must be a command string or an array of arguments")
+	}
+
+	*h = words
+	return nil
+}
+
+// String returns the command as a string that SplitCommand splits back into
+// the same arguments
+func (h HookCommand) String() string {
+	words := []string{}
+	for _, word := range h {
+		if word == "" || strings.ContainsAny(word, " \t\n'\"\\$`") {
+			word = "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+		}
+		words = append(words, word)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Payload written to a hook's stdin as JSON
+type hookPayload struct {
+	Event       HookEvent `json:"event"`                 // Event that triggered the hook
+	Note        Metadata  `json:"note"`                  // Metadata of the note
+	Path        string    `json:"path"`                  // Path of the note's file
+	PublishPath string    `json:"publishPath,omitempty"` // Path the note is published to
+}
+
+// Run the hook configured for an event, if there is one. The hook runs in the
+// note directory with the note's metadata in 'NOTE_' environment variables and
+// as JSON on stdin. An error is returned if the hook exits unsuccessfully
+func (m *Manager) runHook(event HookEvent, note *Note, publishPath string) error {
+	command := m.Config.Hooks[event]
+	if len(command) == 0 {
+		return nil
+	}
+
+	m.log().Info("running hook", "event", event, "command", command.String(), "filename", note.Filename)
+
+	payload, err := json.Marshal(hookPayload{
+		Event:       event,
+		Note:        note.Metadata,
+		Path:        m.NotePath(note.Filename),
+		PublishPath: publishPath,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Hooks run in the notes directory if it exists on disk, which it may not
+	// for notes kept in other stores
+	cmd := exec.Command(command[0], command[1:]...)
+	if info, err := os.Stat(m.Directory()); err == nil && info.IsDir() {
+		cmd.Dir = m.Directory()
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"NOTE_HOOK="+string(event),
+		"NOTE_FILENAME="+note.Filename,
+		"NOTE_TITLE="+note.Title,
+		"NOTE_PATH="+m.NotePath(note.Filename),
+		"NOTE_DIRECTORY="+m.Directory(),
+		"NOTE_AUTHOR="+note.Author,
+		"NOTE_CREATED_AT="+note.CreatedAt.Format(time.RFC3339),
+		"NOTE_UPDATED_AT="+note.UpdatedAt.Format(time.RFC3339),
+		"NOTE_ENCRYPTED="+strconv.FormatBool(note.Encrypted),
+		"NOTE_PUBLISH_PATH="+publishPath,
+	)
+
+	if err := cmd.Run(); err != nil {
+		m.log().Error("hook failed", "event", event, "err", err)
+		return wrapf(ErrHookFailed, "%s hook failed (err: %v)", event, err)
+	}
+
+	return nil
+}
+
+// Run the hook configured for an event after an operation has finished. The
+// operation has already happened, so failures are only logged
+func (m *Manager) runPostHook(event HookEvent, note *Note, publishPath string) {
+	if err := m.runHook(event, note, publishPath); err != nil {
+		m.log().Warn("ignoring failed hook", "event", event, "err", err)
+	}
+}
@@ -0,0 +1,137 @@
+package note_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that hooks receive note metadata in the environment and on stdin
+func TestHooks(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+
+	out := path.Join(t.TempDir(), "hooks.log")
+	script := `echo "$NOTE_HOOK $NOTE_FILENAME $NOTE_AUTHOR $NOTE_PUBLISH_PATH" >> ` + out
+	manager.Config.Hooks = map[note.HookEvent]note.HookCommand{
+		note.HookPostCreate:  {"sh", "-c", script},
+		note.HookPreEdit:     {"sh", "-c", script},
+		note.HookPostEdit:    {"sh", "-c", script},
+		note.HookPostPublish: {"sh", "-c", script},
+		note.HookPostDelete:  {"sh", "-c", script + "; cat >> " + out},
+	}
+
+	require.Nil(manager.CreateNote("note-1"))
+	require.Nil(manager.OpenNote("note-1"))
+	published, err := manager.PublishNote("note-1", t.TempDir())
+	require.Nil(err)
+	require.Nil(manager.DeleteNote("note-1"))
+
+	content, err := os.ReadFile(out)
+	require.Nil(err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Equal("post_create note-1 Ethan ", lines[0])
+	require.Equal("pre_edit note-1 Ethan ", lines[1])
+	require.Equal("post_edit note-1 Ethan ", lines[2])
+	require.Equal("post_publish note-1 Ethan "+published, lines[3])
+	require.Equal("post_delete note-1 Ethan ", lines[4])
+
+	// The metadata is also written as JSON on stdin
+	payload := map[string]any{}
+	require.Nil(json.Unmarshal([]byte(lines[5]), &payload))
+	require.Equal("post_delete", payload["event"])
+	require.Equal("note-1", payload["note"].(map[string]any)["filename"])
+}
+
+// Test that failing pre-hooks abort the operation
+func TestPreHookAborts(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	require.Nil(manager.CreateNote("note-1"))
+
+	manager.Config.Hooks = map[note.HookEvent]note.HookCommand{
+		note.HookPreCreate:  {"false"},
+		note.HookPreDelete:  {"false"},
+		note.HookPrePublish: {"false"},
+		note.HookPostEdit:   {"false"},
+	}
+
+	err = manager.CreateNote("note-2")
+	require.True(errors.Is(err, note.ErrHookFailed))
+	require.Equal("pre_create hook failed (err: exit status 1)", err.Error())
+	require.Nil(manager.GetNote("note-2"))
+
+	err = manager.DeleteNote("note-1")
+	require.True(errors.Is(err, note.ErrHookFailed))
+	require.NotNil(manager.GetNote("note-1"))
+
+	directory := t.TempDir()
+	_, err = manager.PublishNote("note-1", directory)
+	require.True(errors.Is(err, note.ErrHookFailed))
+	_, err = os.Stat(path.Join(directory, "note-1.md"))
+	require.True(os.IsNotExist(err))
+
+	// Failing post-hooks don't fail the operation
+	require.Nil(manager.OpenNote("note-1"))
+}
+
+// Test that pre-create hooks run in the notes directory of a new vault
+func TestPreCreateHookNewVault(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	dir := t.TempDir()
+	manager, err := note.New(
+		note.WithConfigPath(path.Join(dir, "config.json")),
+		note.WithManagerPath(path.Join(dir, "manager.json")),
+		note.WithDirectory(path.Join(dir, "entries")),
+	)
+	require.Nil(err)
+
+	out := path.Join(dir, "hooks.log")
+	manager.Config.Hooks = map[note.HookEvent]note.HookCommand{
+		note.HookPreCreate: {"sh", "-c", "pwd > " + out},
+	}
+
+	require.Nil(manager.CreateNote("note-1"))
+	require.NotNil(manager.GetNote("note-1"))
+
+	content, err := os.ReadFile(out)
+	require.Nil(err)
+	require.Equal(path.Join(dir, "entries"), strings.TrimSpace(string(content)))
+}
+
+// Test reading hooks from the config file and setting them by key
+func TestHookConfig(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	config := &note.Config{}
+
+	require.Nil(json.Unmarshal([]byte(`{"hooks": {"post_edit": ["git", "commit", "-am", "edited note"], "pre_publish": "./lint.sh --strict"}}`), config))
+	require.Equal(note.HookCommand{"git", "commit", "-am", "edited note"}, config.Hooks[note.HookPostEdit])
+	require.Equal(note.HookCommand{"./lint.sh", "--strict"}, config.Hooks[note.HookPrePublish])
+
+	value, err := config.Get("hooks.post_edit")
+	require.Nil(err)
+	require.Equal("git commit -am 'edited note'", value)
+
+	require.Nil(config.Set("hooks.post_create", "echo 'new note'"))
+	require.Equal(note.HookCommand{"echo", "new note"}, config.Hooks[note.HookPostCreate])
+
+	require.Nil(config.Set("hooks.post_create", ""))
+	_, ok := config.Hooks[note.HookPostCreate]
+	require.False(ok)
+
+	_, err = config.Get("hooks.on_save")
+	require.True(errors.Is(err, note.ErrInvalidConfig))
+}
@@ -1,18 +1,165 @@
 package note
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"log"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+	"sync"
 )
 
-// Change default log flags
-func init() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile | log.Lmsgprefix)
-	log.SetPrefix("\t")
+// Names of the log levels, from most to least verbose
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// Default maximum size of a log file, in kilobytes, before it is rotated
+const defaultLogMaxSize = 1024
+
+// Default number of rotated log files to keep
+const defaultLogKeep = 3
+
+// ParseLogLevel returns the level with the provided name
+func ParseLogLevel(name string) (slog.Level, error) {
+	level := slog.LevelInfo
+	if err := level.UnmarshalText([]byte(name)); err != nil || !strings.EqualFold(level.String(), name) {
+		return level, wrapf(ErrInvalidConfig, "invalid log level '%s'", name)
+	}
+
+	return level, nil
+}
+
+// LogFile is a writer that appends to a file, rotating it once it grows past a
+// maximum size. Rotated files are renamed with a numbered suffix, such as
+// 'note.log.1', and the oldest are removed. It is safe for concurrent use
+type LogFile struct {
+	Path    string // Path of the log file
+	MaxSize int64  // Size in bytes after which the file is rotated
+	Keep    int    // Number of rotated files to keep
+
+	mu sync.Mutex
+}
+
+// Write appends to the log file, rotating it first if it is too large
+func (f *LogFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if info, err := os.Stat(f.Path); err == nil && f.MaxSize > 0 && info.Size()+int64(len(p)) > f.MaxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := os.MkdirAll(path.Dir(f.Path), 0755); err != nil {
+		return 0, err
+	}
+
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return file.Write(p)
+}
+
+// Shift every rotated file up by one, removing the oldest
+func (f *LogFile) rotate() error {
+	os.Remove(fmt.Sprintf("%s.%d", f.Path, f.Keep))
+
+	for i := f.Keep - 1; i >= 1; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", f.Path, i), fmt.Sprintf("%s.%d", f.Path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if f.Keep < 1 {
+		return os.Remove(f.Path)
+	}
+
+	return os.Rename(f.Path, f.Path+".1")
+}
+
+// teeHandler sends every record to each handler that is enabled for its level
+type teeHandler []slog.Handler
+
+func (h teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h teeHandler) Handle(ctx context.Context, record slog.Record) error {
+	for _, handler := range h {
+		if !handler.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, record.Clone()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := teeHandler{}
+	for _, handler := range h {
+		handlers = append(handlers, handler.WithAttrs(attrs))
+	}
+
+	return handlers
+}
+
+func (h teeHandler) WithGroup(name string) slog.Handler {
+	handlers := teeHandler{}
+	for _, handler := range h {
+		handlers = append(handlers, handler.WithGroup(name))
+	}
+
+	return handlers
+}
+
+// Return a logger that writes to the provided logger and to the log file in
+// the config, if one is set
+func (c *Config) withLogFile(logger *slog.Logger) (*slog.Logger, error) {
+	if c.LogFile == "" {
+		return logger, nil
+	}
+
+	level := slog.LevelInfo
+	if c.LogLevel != "" {
+		var err error
+		if level, err = ParseLogLevel(c.LogLevel); err != nil {
+			return nil, err
+		}
+	}
+
+	maxSize, keep := c.LogMaxSize, c.LogKeep
+	if maxSize == 0 {
+		maxSize = defaultLogMaxSize
+	}
+	if keep == 0 {
+		keep = defaultLogKeep
+	}
+
+	file := &LogFile{Path: c.LogFile, MaxSize: int64(maxSize) * 1024, Keep: keep}
+	handler := slog.NewTextHandler(file, &slog.HandlerOptions{Level: level})
+
+	return slog.New(teeHandler{logger.Handler(), handler}), nil
 }
 
-// Option to suppress logs for command line usage
+// SuppressLogs discards logs written to the default logger, which managers use
+// when they aren't given a logger
+//
+// Deprecated: pass a logger to New instead
 func SuppressLogs() {
-	// Suppress logs for command line usage
 	log.SetOutput(io.Discard)
 }
@@ -0,0 +1,84 @@
+package note_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test parsing log levels
+func TestParseLogLevel(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+
+	for _, name := range note.LogLevels {
+		_, err := note.ParseLogLevel(name)
+		require.Nil(err)
+	}
+
+	level, err := note.ParseLogLevel("warn")
+	require.Nil(err)
+	require.Equal(slog.LevelWarn, level)
+
+	_, err = note.ParseLogLevel("loud")
+	require.True(errors.Is(err, note.ErrInvalidConfig))
+}
+
+// Test that log files are rotated once they grow too large
+func TestLogFileRotation(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	root := t.TempDir()
+	file := &note.LogFile{Path: path.Join(root, "logs/note.log"), MaxSize: 20, Keep: 2}
+
+	for i := 1; i <= 5; i++ {
+		_, err := fmt.Fprintf(file, "line %d of the log\n", i)
+		require.Nil(err)
+	}
+
+	// Only the newest lines are kept
+	for name, line := range map[string]string{"note.log": "line 5", "note.log.1": "line 4", "note.log.2": "line 3"} {
+		content, err := os.ReadFile(path.Join(root, "logs", name))
+		require.Nil(err)
+		require.Equal(line+" of the log\n", string(content))
+	}
+
+	_, err := os.Stat(path.Join(root, "logs/note.log.3"))
+	require.True(os.IsNotExist(err))
+}
+
+// Test that managers write logs to the log file in the config
+func TestConfigLogFile(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+
+	logFile := path.Join(t.TempDir(), "note.log")
+	require.Nil(manager.Config.Set("log_file", logFile))
+	require.Nil(manager.Config.Set("log_level", "debug"))
+	require.Nil(manager.Config.Save())
+
+	manager, err = note.GetManager(note.WithLogger(slog.New(slog.NewTextHandler(&strings.Builder{}, nil))))
+	require.Nil(err)
+	require.NotNil(manager.DeleteNote("missing"))
+
+	content, err := os.ReadFile(logFile)
+	require.Nil(err)
+	require.Contains(string(content), `level=DEBUG msg="reading manager information"`)
+	require.Contains(string(content), `level=ERROR msg="note not found" filename=missing`)
+
+	// Invalid log levels are rejected
+	manager.Config.LogLevel = "loud"
+	require.Nil(manager.Config.Save())
+
+	_, err = note.GetManager()
+	require.True(errors.Is(err, note.ErrInvalidConfig))
+}
@@ -3,10 +3,8 @@ package note
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
-	"log"
+	"log/slog"
 	"os"
-	"os/exec"
 	"path"
 	"strings"
 	"time"
@@ -17,6 +15,75 @@ import (
 type Manager struct {
 	Notes  []*Note `json:"notes"` // List of notes managed
 	Config *Config `json:"-"`     // Config to manage notes
+
+	Passphrase func() ([]byte, error) `json:"-"` // Function that provides the passphrase for encrypted notes
+
+	configPath  string                   // Path to the config file, defaulting to the vault's config path
+	managerPath string                   // Path to the manager file, defaulting to the vault's manager path
+	clock       func() time.Time         // Function that returns the current time
+	logger      *slog.Logger             // Logger for the manager's logs
+	store       Store                    // Store for the config file, manager file, and note files
+	directory   string                   // Directory where notes are stored, overriding the config's directory if set
+	events      eventBus                 // Subscribers to changes made to notes
+	scanned     map[string]fileState     // State of each note file when the directory was last scanned
+	cache       map[string]cachedContent // Content of note files when they were last read or written
+}
+
+// Return the store for the manager's files
+func (m *Manager) files() Store {
+	if m.store == nil {
+		return OSStore{}
+	}
+	return m.store
+}
+
+// Directory returns the directory where notes are stored, which is the
+// config's directory unless New was given another one with WithDirectory
+func (m *Manager) Directory() string {
+	if m.directory != "" {
+		return m.directory
+	}
+	return m.Config.Directory
+}
+
+// Return the path to the manager file
+func (m *Manager) managerFile() string {
+	if m.managerPath == "" {
+		return managerPath
+	}
+	return m.managerPath
+}
+
+// Return the current time
+func (m *Manager) now() time.Time {
+	if m.clock == nil {
+		return time.Now()
+	}
+	return m.clock()
+}
+
+// Return the logger of the manager, which is the default logger unless one was
+// provided to New
+func (m *Manager) log() *slog.Logger {
+	if m.logger == nil {
+		return slog.Default()
+	}
+	return m.logger
+}
+
+// Logger returns the logger the manager writes its logs to, so code that wraps
+// the manager can log alongside it
+func (m *Manager) Logger() *slog.Logger {
+	return m.log()
+}
+
+// Attach a config to the manager, so it is loaded and saved with the manager's
+// config path, store, and logger
+func (m *Manager) attachConfig(config *Config) {
+	config.path = m.configPath
+	config.store = m.store
+	config.logger = m.logger
+	m.Config = config
 }
 
 // Return status of if the manager contains the filename. If the manager contains the filename
@@ -34,329 +101,537 @@ func (m *Manager) contains(filename string) (bool, int) {
 	return false, -1
 }
 
-// Create a new note with the provided filename, save it to storage, and add it to the manager
+// Return the path of the file where the note with the provided filename is stored.
+// Encrypted notes are stored with an additional '.enc' extension
+func (m *Manager) NotePath(filename string) string {
+	if ok, index := m.contains(filename); ok && m.Notes[index].Encrypted {
+		return path.Join(m.Directory(), filename+".md.enc")
+	}
+
+	return path.Join(m.Directory(), filename+".md")
+}
+
+// Create a new note with the provided filename, save it to storage, and add it to the manager.
+// The note's title is made from the filename
 func (m *Manager) CreateNote(filename string) error {
-	log.Printf("[INFO]: creating new note with filename '%s'", filename)
+	return m.CreateNoteTitled(filename, "")
+}
+
+// Create a new note with the provided title, which can be written in any
+// script. The filename is a slug of the title, numbered if another note
+// already uses it, and is returned
+func (m *Manager) CreateNoteWithTitle(title string) (string, error) {
+	if strings.TrimSpace(title) == "" {
+		m.log().Error("empty note title")
+		return "", wrapf(ErrInvalidName, "invalid title '%s'", title)
+	}
+
+	filename := m.uniqueFilename(title)
+	return filename, m.CreateNoteTitled(filename, title)
+}
+
+// Create a new note with a filename and a separate title, making the title
+// from the filename if it is empty
+func (m *Manager) CreateNoteTitled(filename string, title string) error {
+	m.log().Info("creating new note", "filename", filename, "title", title)
 
 	filename = strings.ToLower(filename)
 
 	// Check if a duplicate filename exists
 	if ok, _ := m.contains(filename); ok {
-		log.Printf("[ERR]: duplicate note name '%s'", filename)
-		return fmt.Errorf("duplicate note name '%s'", filename)
+		m.log().Error("duplicate note name", "filename", filename)
+		return wrapf(ErrDuplicate, "duplicate note name '%s'", filename)
 	}
 
 	// Create a new note
-	note, err := NewNote(m.Config, filename)
+	note, err := newNote(m.Config, filename, title)
 	if err != nil {
-		log.Printf("[ERR]: failed to create new note (err: %v)", err)
+		m.log().Error("failed to create new note", "err", err)
+		return err
+	}
+	note.CreatedAt = m.now()
+	note.UpdatedAt = note.CreatedAt
+	note.Fields = m.Config.defaultFields()
+
+	// Create the note directory on the first note, before hooks run in it
+	if err := m.files().MkdirAll(m.Directory(), 0755); err != nil {
+		m.log().Error("failed to create note directory", "err", err)
 		return err
 	}
 
-	log.Printf("[INFO]: successfully created new note with filename '%s'", filename)
-	log.Printf("[INFO]: saving note to file")
+	if err := m.runHook(HookPreCreate, note, ""); err != nil {
+		return err
+	}
 
-	// Save the note's content to the filesystem
-	filepath := path.Join(m.Config.Directory, filename+".md")
-	log.Printf("[INFO]: saving note to file '%s'", filepath)
+	m.log().Debug("successfully created new note", "filename", filename)
+	m.log().Debug("saving note to file")
 
-	if err = os.WriteFile(filepath, []byte(note.Content), 0600); err != nil {
-		log.Printf("[ERR]: failed to save note to file (err: %v)", err)
+	// Save the note's content to the store
+	if err = m.writeContent(note); err != nil {
+		m.log().Error("failed to save note to file", "err", err)
 		return err
 	}
 
-	log.Printf("[INFO]: successfully saved note to file")
+	m.log().Debug("successfully saved note to file")
 
 	// Add the note to the manager
 	m.Notes = append(m.Notes, note)
 
-	log.Printf("[INFO]: saving manager")
+	m.log().Debug("saving manager")
 
 	// Save the manager to storage
 	if err := m.Save(); err != nil {
-		log.Printf("[ERR]: failed to save manager (err: %v)", err)
+		m.log().Error("failed to save manager", "err", err)
 		return err
 	}
+
+	m.emitChange(EventCreated, filename, nil, note)
+	m.runPostHook(HookPostCreate, note, "")
 	return nil
 }
 
 // Delete an note with the provided filename, remove it from storage, and remove it from the manager
 func (m *Manager) DeleteNote(filename string) error {
-	log.Printf("[INFO]: deleting note with filename '%s'", filename)
+	m.log().Info("deleting note", "filename", filename)
 
 	filename = strings.ToLower(filename)
 
 	// Find the note in the manager
 	index, ok := -1, false
 	if ok, index = m.contains(filename); !ok {
-		log.Printf("[ERR]: note with name '%s' not found", filename)
-		return fmt.Errorf("note with name '%s' not found", filename)
+		m.log().Error("note not found", "filename", filename)
+		return wrapf(ErrNotFound, "note with name '%s' not found", filename)
 	}
 
-	log.Printf("[INFO]: successfully found note with filename '%s', removing", filename)
+	m.log().Debug("successfully found note, removing", "filename", filename)
+
+	note := m.Notes[index]
+	if err := m.runHook(HookPreDelete, note, ""); err != nil {
+		return err
+	}
 
 	// Remove the note from the manager
+	filepath := m.NotePath(filename)
 	m.Notes = append(m.Notes[:index], m.Notes[index+1:]...)
+	delete(m.cache, filename)
 
-	log.Printf("[INFO]: successfully removed note with filename '%s', deleting associated file", filename)
+	m.log().Debug("successfully removed note, deleting associated file", "filename", filename)
 
 	// Remove the note from storage
-	filepath := path.Join(m.Config.Directory, filename+".md")
-	log.Printf("[INFO]: removing note at file '%s'", filepath)
+	m.log().Info("removing note", "path", filepath)
 
-	if err := os.Remove(filepath); err != nil {
-		log.Printf("[ERR]: failed to remove note file (err: %v)", err)
+	if err := m.files().Remove(filepath); err != nil {
+		m.log().Error("failed to remove note file", "err", err)
 		return err
 	}
 
-	log.Printf("[INFO]: successfully removed note file")
-	log.Printf("[INFO]: saving manager")
+	m.log().Debug("successfully removed note file")
+	m.log().Debug("saving manager")
 
 	// Save the manager to storage
 	if err := m.Save(); err != nil {
-		log.Printf("[ERR]: failed to save manager (err: %v)", err)
+		m.log().Error("failed to save manager", "err", err)
 		return err
 	}
 
+	old := note.Metadata
+	m.emitChange(EventDeleted, filename, &old, nil)
+	m.runPostHook(HookPostDelete, note, "")
 	return nil
 }
 
 // Open an note using the provided text editor
 func (m *Manager) OpenNote(filename string) error {
-	log.Printf("[INFO]: opening note with filename '%s'", filename)
+	return m.OpenNoteAt(filename, 0)
+}
+
+// Open an note using the provided text editor at a one-based line. Lines that
+// are not positive open the note at its start
+func (m *Manager) OpenNoteAt(filename string, line int) error {
+	m.log().Info("opening note", "filename", filename, "line", line)
 
 	filename = strings.ToLower(filename)
 
 	// Make sure the filename exists in the manager
 	index, ok := -1, false
 	if ok, index = m.contains(filename); !ok {
-		log.Printf("[ERR]: filename '%s' does not exist", filename)
-		return fmt.Errorf("note with name '%s' not found", filename)
+		m.log().Error("note not found", "filename", filename)
+		return wrapf(ErrNotFound, "note with name '%s' not found", filename)
 	}
 
-	log.Printf("[INFO]: found note with filename '%s'", filename)
-	log.Printf("[INFO]: opening note in editor")
+	m.log().Debug("found note", "filename", filename)
+	m.log().Debug("opening note in editor")
 
 	// Get note details
 	note := m.Notes[index]
-	filepath := path.Join(m.Config.Directory, filename+".md")
+	filepath := m.NotePath(filename)
+
+	if err := m.runHook(HookPreEdit, note, ""); err != nil {
+		return err
+	}
+
+	old := note.Metadata
+
+	// Encrypted notes are edited through a decrypted temporary file
+	if note.Encrypted {
+		if err := m.openEncryptedNote(note, line); err != nil {
+			return err
+		}
 
-	log.Printf("[INFO]: getting note details at file '%s'", filepath)
-	log.Printf("[INFO]: command = '%s %s'", m.Config.Editor, filepath)
+		m.emitChange(EventUpdated, filename, &old, note)
+		m.runPostHook(HookPostEdit, note, "")
+		return m.checkFields(note)
+	}
 
-	// Create editor command
-	cmd := exec.Command(m.Config.Editor, filepath)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	m.log().Debug("getting note details", "path", filepath)
 
 	// Open the note in the editor
-	if err := cmd.Run(); err != nil {
-		log.Printf("[ERR]: failed to open note in editor (err: %v)", err)
+	if err := m.runEditor(filepath, line); err != nil {
+		m.log().Error("failed to open note in editor", "err", err)
 		return err
 	}
 
-	log.Printf("[INFO]: note edited, continuing")
-	log.Printf("[INFO]: updating note metadata")
+	m.log().Debug("note edited, continuing")
+	m.log().Debug("updating note metadata")
 
 	// Save the note with the manager
-	note.UpdatedAt = time.Now()
+	note.UpdatedAt = m.now()
 
-	content, err := os.ReadFile(filepath)
+	content, err := m.files().ReadFile(filepath)
 	if err != nil {
-		log.Printf("[ERR]: failed to read note file (err: %v)", err)
+		m.log().Error("failed to read note file", "err", err)
 		return err
 	}
 	note.Content = string(content)
+	note.unloaded = false
+
+	note.syncFields()
 
-	log.Printf("[INFO]: saving manager")
+	m.log().Debug("saving manager")
 
 	// Save the manager to storage
 	if err := m.Save(); err != nil {
-		log.Printf("[ERR]: failed to save manager (err: %v)", err)
+		m.log().Error("failed to save manager", "err", err)
 		return err
 	}
 
-	return nil
+	m.emitChange(EventUpdated, filename, &old, note)
+	m.runPostHook(HookPostEdit, note, "")
+	return m.checkFields(note)
 }
 
-// Return a list of all notes in the manager
-func (m *Manager) GetNotes() []*Note {
-	log.Printf("[INFO]: listing all notes")
+// Change the title of an existing note without changing its filename or content
+func (m *Manager) SetTitle(filename string, title string) error {
+	m.log().Info("setting note title", "filename", filename, "title", title)
 
-	return m.Notes
+	filename = strings.ToLower(filename)
+
+	// Make sure the filename exists in the manager
+	index, ok := -1, false
+	if ok, index = m.contains(filename); !ok {
+		m.log().Error("note not found", "filename", filename)
+		return wrapf(ErrNotFound, "note with name '%s' not found", filename)
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		m.log().Error("empty note title")
+		return wrapf(ErrInvalidName, "invalid title '%s'", title)
+	}
+
+	note := m.Notes[index]
+	old := note.Metadata
+	note.Title = title
+	note.UpdatedAt = m.now()
+
+	m.log().Debug("saving manager")
+
+	// Save the manager to storage
+	if err := m.Save(); err != nil {
+		m.log().Error("failed to save manager", "err", err)
+		return err
+	}
+
+	m.emitChange(EventUpdated, filename, &old, note)
+	return nil
 }
 
-// Return an note with the provided filename. If no matching note can
-// be found, this method will return nil
-func (m *Manager) GetNote(filename string) *Note {
-	log.Printf("[INFO]: getting note with filename '%s'", filename)
+// Replace the content of an existing note, update its metadata, and save it to storage
+func (m *Manager) UpdateNote(filename string, content string) error {
+	m.log().Info("updating note", "filename", filename)
 
-	for _, note := range m.Notes {
-		if note.Filename == filename {
-			log.Printf("[INFO]: found note with filename '%s'", filename)
-			return note
+	filename = strings.ToLower(filename)
+
+	// Make sure the filename exists in the manager
+	index, ok := -1, false
+	if ok, index = m.contains(filename); !ok {
+		m.log().Error("note not found", "filename", filename)
+		return wrapf(ErrNotFound, "note with name '%s' not found", filename)
+	}
+
+	m.log().Debug("updating note metadata")
+
+	// Update the note, keeping the content of encrypted notes out of memory
+	note := m.Notes[index]
+	old := note.Metadata
+	if note.Encrypted {
+		if err := m.writeEncrypted(note, []byte(content)); err != nil {
+			m.log().Error("failed to encrypt note", "err", err)
+			return err
 		}
+	} else {
+		note.Content = content
+		note.unloaded = false
+		note.syncFields()
+	}
+	note.UpdatedAt = m.now()
+
+	m.log().Debug("saving manager")
+
+	// Save the manager to storage
+	if err := m.Save(); err != nil {
+		m.log().Error("failed to save manager", "err", err)
+		return err
 	}
 
+	m.emitChange(EventUpdated, filename, &old, note)
 	return nil
 }
 
-// Save all note-related metadata to storage
-func (m *Manager) Save() error {
-	log.Printf("[INFO]: saving manager information")
+// Save a published markdown version of a note, including its metadata, to the
+// provided directory. The filepath of the published note is returned
+func (m *Manager) PublishNote(filename string, directory string) (string, error) {
+	return m.PublishNoteAs(filename, directory, DefaultExportFormat)
+}
 
-	// Save all note metadata
-	file, err := json.MarshalIndent(m, "", "    ")
+// Save a published version of a note to the provided directory in the format
+// of a registered exporter. The filepath of the published note is returned
+func (m *Manager) PublishNoteAs(filename string, directory string, format string) (string, error) {
+	m.log().Info("publishing note", "filename", filename, "directory", directory, "format", format)
+
+	exporter, err := LookupExporter(format)
 	if err != nil {
-		log.Printf("[ERR]: failed to marshal manager struct (err: %v)", err)
-		return err
+		m.log().Error("exporter not found", "format", format)
+		return "", err
 	}
 
-	// Ensure the directory exists
-	err = os.MkdirAll(path.Dir(managerPath), 0755)
+	filename = strings.ToLower(filename)
+
+	// Make sure the filename exists in the manager
+	index, ok := -1, false
+	if ok, index = m.contains(filename); !ok {
+		m.log().Error("note not found", "filename", filename)
+		return "", wrapf(ErrNotFound, "note with name '%s' not found", filename)
+	}
+
+	// Encrypted notes are never published
+	note := m.Notes[index]
+	if note.Encrypted {
+		m.log().Error("note is encrypted", "filename", filename)
+		return "", wrapf(ErrEncrypted, "note '%s' is encrypted, decrypt it before publishing", filename)
+	}
+
+	// Save the note to the directory
+	filepath := path.Join(directory, note.Filename+exporter.Extension)
+
+	if err := m.runHook(HookPrePublish, note, filepath); err != nil {
+		return "", err
+	}
+
+	if err := m.LoadContent([]*Note{note}); err != nil {
+		return "", err
+	}
+
+	content, err := exporter.Export(note)
 	if err != nil {
-		log.Printf("[ERR]: failed to create config directory (err: %v)", err)
-		return err
+		m.log().Error("failed to export note", "format", format, "err", err)
+		return "", err
 	}
 
-	// Write the JSON object to the default filepath
-	if err = os.WriteFile(managerPath, file, 0600); err != nil {
-		log.Printf("[ERR]: failed to save manager struct to file (err: %v)", err)
-		return err
+	if err := os.WriteFile(filepath, content, 0644); err != nil {
+		m.log().Error("failed to publish note", "err", err)
+		return "", err
 	}
 
-	log.Printf("[INFO]: successfully saved manager struct to file")
-	log.Printf("[INFO]: saving notes to files")
+	m.log().Debug("successfully published note", "path", filepath)
 
-	// Save all note content to a file represented by their filename
-	for _, note := range m.Notes {
-		log.Printf("[INFO]: saving note '%s' to file", note.Filename)
+	m.runPostHook(HookPostPublish, note, filepath)
+	return filepath, nil
+}
 
-		filepath := path.Join(m.Config.Directory, note.Filename+".md")
-		log.Printf("[INFO]: saving note to file '%s'", filepath)
+// Return a list of all notes whose filename or content contains the provided
+// query. The search is case-insensitive, and only matches the filename of
+// encrypted notes
+func (m *Manager) SearchNotes(query string) []*Note {
+	m.log().Debug("searching notes", "query", query)
+	m.loadAll()
 
-		if err := os.WriteFile(filepath, []byte(note.Content), 0600); err != nil {
-			log.Printf("[ERR]: failed to save note '%s' to file (err: %v)", note.Filename, err)
-			return err
+	query = strings.ToLower(query)
+
+	results := []*Note{}
+	for _, note := range m.Notes {
+		if strings.Contains(note.Filename, query) || (!note.Encrypted && strings.Contains(strings.ToLower(note.Content), query)) {
+			results = append(results, note)
 		}
-		log.Printf("[INFO]: successfully saved note '%s' to file", note.Filename)
 	}
 
-	log.Printf("[INFO]: successfully saved notes to files")
-	log.Printf("[INFO]: saving config file")
+	return results
+}
 
-	// Save config file
-	if err := m.Config.Save(); err != nil {
-		log.Printf("[ERR]: failed to save config file (err: %v)", err)
-		return err
+// Return a list of all notes in the manager, with their content loaded. Use
+// ListNotes to get notes without loading their content
+func (m *Manager) GetNotes() []*Note {
+	m.log().Debug("listing all notes")
+	m.loadAll()
+
+	return m.Notes
+}
+
+// Return an note with the provided filename, with its content loaded. If no
+// matching note can be found, this method will return nil
+func (m *Manager) GetNote(filename string) *Note {
+	m.log().Debug("getting note", "filename", filename)
+
+	for _, note := range m.Notes {
+		if note.Filename == filename {
+			m.log().Debug("found note", "filename", filename)
+			m.LoadContent([]*Note{note})
+			return note
+		}
 	}
 
 	return nil
 }
 
-// Load related note metadata from storage
-func (m *Manager) Load() error {
-	log.Printf("[INFO]: reading manager information")
+// Return the content of the note with the provided filename, decrypting it
+// with the manager's passphrase if the note is encrypted
+func (m *Manager) ReadNote(filename string) (string, error) {
+	m.log().Debug("reading note", "filename", filename)
 
-	// Read in the provided filename
-	file, err := os.ReadFile(managerPath)
-	if err != nil {
-		log.Printf("[ERR]: failed to read manager file (err: %v)", err)
-		return err
+	filename = strings.ToLower(filename)
+
+	// Make sure the filename exists in the manager
+	index, ok := -1, false
+	if ok, index = m.contains(filename); !ok {
+		m.log().Error("note not found", "filename", filename)
+		return "", wrapf(ErrNotFound, "note with name '%s' not found", filename)
 	}
 
-	// Unmarshal the file into the Manager struct
-	if err = json.Unmarshal(file, m); err != nil {
-		log.Printf("[ERR]: failed to parse manager file (err: %v)", err)
-		return err
+	note := m.Notes[index]
+	if !note.Encrypted {
+		if err := m.LoadContent([]*Note{note}); err != nil {
+			return "", err
+		}
+		return note.Content, nil
 	}
 
-	log.Printf("[INFO]: successfully read into manager struct")
-	log.Printf("[INFO]: reading notes from files")
+	content, err := m.readEncrypted(note)
+	if err != nil {
+		m.log().Error("failed to decrypt note", "err", err)
+		return "", err
+	}
 
-	// Read all note content from files
-	for _, note := range m.Notes {
-		log.Printf("[INFO]: reading note '%s' from file", note.Filename)
+	return string(content), nil
+}
 
-		filepath := path.Join(m.Config.Directory, note.Filename+".md")
-		log.Printf("[INFO]: reading note from file '%s'", filepath)
+// Save all note-related metadata to storage. The config is saved on its own
+// with Config.Save
+func (m *Manager) Save() error {
+	if err := m.saveMetadata(); err != nil {
+		return err
+	}
+	m.log().Debug("saving notes to files")
 
-		content, err := os.ReadFile(filepath)
-		if err != nil {
-			log.Printf("[ERR]: failed to read note '%s' from file (err: %v)", note.Filename, err)
+	// Save the content of every note that changed to a file represented by its
+	// filename. Encrypted notes are only ever written when their content changes,
+	// and notes whose content was never loaded are left as they are
+	for _, note := range m.Notes {
+		if err := m.writeContent(note); err != nil {
+			m.log().Error("failed to save note to file", "filename", note.Filename, "err", err)
 			return err
 		}
-		note.Content = string(content)
-
-		log.Printf("[INFO]: successfully read note '%s' from file", note.Filename)
 	}
 
-	log.Printf("[INFO]: successfully read notes from files")
-	log.Printf("[INFO]: reading config file")
+	m.log().Debug("successfully saved notes to files")
+	return nil
+}
+
+// Save the metadata of every note to the manager file, without writing the
+// notes' files
+func (m *Manager) saveMetadata() error {
+	m.log().Debug("saving manager information")
 
-	// Read config file
-	if err := m.Config.Load(); err != nil {
-		log.Printf("[ERR]: failed to read config file (err: %v)", err)
+	// Save all note metadata
+	file, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		m.log().Error("failed to marshal manager struct", "err", err)
 		return err
 	}
 
-	return nil
-}
+	// Ensure the directory exists
+	err = m.files().MkdirAll(path.Dir(m.managerFile()), 0755)
+	if err != nil {
+		m.log().Error("failed to create config directory", "err", err)
+		return err
+	}
 
-// GetManager returns an active instance of the manager with the stored config
-func GetManager() (*Manager, error) {
-	manager := &Manager{
-		Config: NewConfig(),
+	// Write the JSON object to the manager file
+	if err = m.files().WriteFile(m.managerFile(), file, 0600); err != nil {
+		m.log().Error("failed to save manager struct to file", "err", err)
+		return err
 	}
 
-	if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) {
-		// Create the config.json file if it doesn't already exist
-		log.Printf("[INFO]: config.json file does not exist, creating it")
+	m.log().Debug("successfully saved manager struct to file")
+	return nil
+}
 
-		if err := manager.Config.Save(); err != nil {
-			log.Printf("[ERR]: failed to save config (err: %v)", err)
-			return nil, err
-		}
-	} else {
-		// The config file already exists, so load it
-		log.Printf("[INFO]: config.json file exists, loading it")
+// Load related note metadata from storage
+func (m *Manager) Load() error {
+	m.log().Debug("reading manager information")
 
-		if err := manager.Config.Load(); err != nil {
-			log.Printf("[ERR]: failed to load config (err: %v)", err)
-			return nil, err
-		}
+	// Read in the provided filename
+	file, err := m.files().ReadFile(m.managerFile())
+	if err != nil {
+		m.log().Error("failed to read manager file", "err", err)
+		return err
 	}
 
-	if _, err := os.Stat(managerPath); errors.Is(err, os.ErrNotExist) {
-		// Create the manager.json file if it doesn't already exist
-		log.Printf("[INFO]: manager.json file does not exist, creating it")
+	// Unmarshal the file into the Manager struct
+	if err = json.Unmarshal(file, m); err != nil {
+		m.log().Error("failed to parse manager file", "err", err)
+		return err
+	}
 
-		if err := manager.Save(); err != nil {
-			log.Printf("[ERR]: failed to create manager.json file")
-			return nil, err
-		}
-	} else {
-		// The manager file already exists, so load it
-		log.Printf("[INFO]: manager.json file exists, loading it")
+	m.log().Debug("successfully read into manager struct")
 
-		if err := manager.Load(); err != nil {
-			log.Printf("[ERR]: failed to load manager (err: %v)", err)
-			return nil, err
-		}
+	// Note content is read when it is needed
+	for _, note := range m.Notes {
+		note.unloaded = true
 	}
 
-	log.Printf("[INFO]: checking existance of note directory")
+	m.log().Debug("reading config file")
 
-	// Create the directory where notes are stored if it doesn't already exist
-	if _, err := os.Stat(manager.Config.Directory); errors.Is(err, os.ErrNotExist) {
-		log.Printf("[INFO]: note directory file does not exist, creating it")
+	// Read config file, which managers created with New only write when asked
+	if _, err := m.files().Stat(m.Config.filepath()); errors.Is(err, os.ErrNotExist) {
+		m.log().Debug("config file does not exist, using defaults")
+	} else if err := m.Config.Load(); err != nil {
+		m.log().Error("failed to read config file", "err", err)
+		return err
+	}
 
-		if err = os.MkdirAll(manager.Config.Directory, 0755); err != nil {
-			log.Printf("[ERR]: failed to create note directory file")
-			return nil, err
+	// Notes created before notes had titles get one from their filename
+	for _, note := range m.Notes {
+		if note.Title == "" {
+			note.Title = m.Config.titleFromFilename(note.Filename)
 		}
 	}
 
-	return manager, nil
+	return nil
+}
+
+// GetManager returns an active instance of the manager with the stored config,
+// creating the config file, manager file, and note directory if they don't exist.
+// Options are passed to New
+func GetManager(opts ...Option) (*Manager, error) {
+	return New(append(opts, WithCreate())...)
 }
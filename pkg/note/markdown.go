@@ -0,0 +1,208 @@
+package note
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Match wiki-style links such as [[note]], [[note#heading]], and [[note|label]]
+var linkMatcher = regexp.MustCompile(`\[\[([^\[\]|#\n]*)(?:#([^\[\]|\n]*))?(?:\|([^\[\]\n]*))?\]\]`)
+
+// Match tags such as #work or #project/apollo, which start after a space or
+// the start of a line so headings and links to headings aren't matched
+var tagMatcher = regexp.MustCompile(`(?:^|[\s(])#([\p{L}_][\p{L}\p{N}_/-]*)`)
+
+// Match ATX headings such as '# Heading'
+var headingMatcher = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)[ \t#]*$`)
+
+// Link represents a wiki-style link from one note to another
+type Link struct {
+	Target  string // Filename of the linked note
+	Heading string // Heading within the linked note, if any
+	Label   string // Label shown instead of the target, if any
+	Line    int    // Zero-based line of the link
+	Start   int    // Byte offset of the start of the link within its line
+	End     int    // Byte offset of the end of the link within its line
+}
+
+// Heading represents a markdown heading in a note
+type Heading struct {
+	Level int    // Level of the heading, from 1 to 6
+	Text  string // Text of the heading
+	Line  int    // Zero-based line of the heading
+}
+
+// Return the lines of the provided content and whether each line is inside a
+// fenced code block, where links and headings are ignored
+func fencedLines(content string) ([]string, []bool) {
+	lines := strings.Split(content, "\n")
+	fenced := make([]bool, len(lines))
+
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			fenced[i] = true
+			continue
+		}
+		fenced[i] = inFence
+	}
+
+	return lines, fenced
+}
+
+// ParseLinks returns every wiki-style link in the provided markdown content
+func ParseLinks(content string) []Link {
+	links := []Link{}
+
+	lines, fenced := fencedLines(content)
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+
+		for _, match := range linkMatcher.FindAllStringSubmatchIndex(line, -1) {
+			link := Link{
+				Target: strings.ToLower(strings.TrimSpace(line[match[2]:match[3]])),
+				Line:   i,
+				Start:  match[0],
+				End:    match[1],
+			}
+			if match[4] >= 0 {
+				link.Heading = strings.TrimSpace(line[match[4]:match[5]])
+			}
+			if match[6] >= 0 {
+				link.Label = strings.TrimSpace(line[match[6]:match[7]])
+			}
+
+			links = append(links, link)
+		}
+	}
+
+	return links
+}
+
+// ParseTags returns the tags in the provided markdown content, lowercased and
+// without their '#', in the order they first appear
+func ParseTags(content string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+
+	lines, fenced := fencedLines(content)
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+
+		for _, match := range tagMatcher.FindAllStringSubmatch(line, -1) {
+			tag := strings.ToLower(match[1])
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// ParseHeadings returns every markdown heading in the provided content
+func ParseHeadings(content string) []Heading {
+	headings := []Heading{}
+
+	lines, fenced := fencedLines(content)
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+
+		if match := headingMatcher.FindStringSubmatch(line); match != nil {
+			headings = append(headings, Heading{
+				Level: len(match[1]),
+				Text:  match[2],
+				Line:  i,
+			})
+		}
+	}
+
+	return headings
+}
+
+// ParseFrontMatter splits YAML front matter delimited by '---' lines from the
+// start of the provided content. The parsed fields and the remaining content
+// are returned. Content without front matter returns nil fields
+func ParseFrontMatter(content string) (map[string]any, string, error) {
+	if !strings.HasPrefix(content, "---\n") && !strings.HasPrefix(content, "---\r\n") {
+		return nil, content, nil
+	}
+
+	// Find the closing delimiter
+	lines := strings.SplitAfter(content, "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], "\r\n") != "---" {
+			continue
+		}
+
+		fields := map[string]any{}
+		if err := yaml.Unmarshal([]byte(strings.Join(lines[1:i], "")), &fields); err != nil {
+			return nil, content, fmt.Errorf("invalid front matter (err: %v)", err)
+		}
+
+		return fields, strings.Join(lines[i+1:], ""), nil
+	}
+
+	return nil, content, fmt.Errorf("front matter is not closed")
+}
+
+// Tags returns the tags of the note, which are the tags in its content and
+// the values of its 'tags' field
+func (a *Note) Tags() []string {
+	tags := ParseTags(a.Content)
+
+	values := []any{a.Fields["tags"]}
+	if list, ok := a.Fields["tags"].([]any); ok {
+		values = list
+	}
+	for _, value := range values {
+		tag, ok := value.(string)
+		tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+		if ok && tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// Links returns every wiki-style link in the note
+func (a *Note) Links() []Link {
+	return ParseLinks(a.Content)
+}
+
+// Headings returns every markdown heading in the note
+func (a *Note) Headings() []Heading {
+	return ParseHeadings(a.Content)
+}
+
+// Return a list of all notes that link to the note with the provided filename
+func (m *Manager) Backlinks(filename string) []*Note {
+	filename = strings.ToLower(filename)
+	m.loadAll()
+
+	results := []*Note{}
+	for _, note := range m.Notes {
+		for _, link := range note.Links() {
+			if link.Target == filename {
+				results = append(results, note)
+				break
+			}
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,93 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test parsing wiki-style links
+func TestParseLinks(t *testing.T) {
+	require := require.New(t)
+
+	content := "# Title\n\nSee [[Note-1]] and [[note-2#Some Heading|the other note]].\n\n```\n[[ignored]]\n```\n"
+	links := note.ParseLinks(content)
+	require.Len(links, 2)
+
+	require.Equal("note-1", links[0].Target)
+	require.Equal("", links[0].Heading)
+	require.Equal(2, links[0].Line)
+	require.Equal(4, links[0].Start)
+	require.Equal(14, links[0].End)
+
+	require.Equal("note-2", links[1].Target)
+	require.Equal("Some Heading", links[1].Heading)
+	require.Equal("the other note", links[1].Label)
+}
+
+// Test parsing headings
+func TestParseHeadings(t *testing.T) {
+	require := require.New(t)
+
+	content := "# Title\n\ntext\n\n## Section ##\n\n```\n# not a heading\n```\n"
+	headings := note.ParseHeadings(content)
+	require.Len(headings, 2)
+
+	require.Equal(note.Heading{Level: 1, Text: "Title", Line: 0}, headings[0])
+	require.Equal(note.Heading{Level: 2, Text: "Section", Line: 4}, headings[1])
+}
+
+// Test parsing tags
+func TestParseTags(t *testing.T) {
+	require := require.New(t)
+
+	content := "# Title\n\n#Work on (#q3/plans), see [[note#heading]] and #work again\n\n```\n#ignored\n```\n#123 ## not\n"
+	require.Equal([]string{"work", "q3/plans"}, note.ParseTags(content))
+
+	// Tags also come from the 'tags' field
+	n := &note.Note{Content: content, Metadata: note.Metadata{Fields: map[string]any{"tags": []any{"#Urgent", "work"}}}}
+	require.Equal([]string{"work", "q3/plans", "urgent"}, n.Tags())
+}
+
+// Test parsing front matter
+func TestParseFrontMatter(t *testing.T) {
+	require := require.New(t)
+
+	// Content with front matter
+	fields, body, err := note.ParseFrontMatter("---\nauthor: Ethan\npriority: 2\n---\n# Title\n")
+	require.Nil(err)
+	require.Equal("Ethan", fields["author"])
+	require.Equal(2, fields["priority"])
+	require.Equal("# Title\n", body)
+
+	// Content without front matter
+	fields, body, err = note.ParseFrontMatter("# Title\n")
+	require.Nil(err)
+	require.Nil(fields)
+	require.Equal("# Title\n", body)
+
+	// Invalid front matter
+	_, _, err = note.ParseFrontMatter("---\nauthor: [\n---\n")
+	require.NotNil(err)
+
+	_, _, err = note.ParseFrontMatter("---\nauthor: Ethan\n")
+	require.NotNil(err)
+}
+
+// Test finding notes that link to a note
+func TestBacklinks(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+
+	require.Nil(manager.CreateNote("note-1"))
+	require.Nil(manager.CreateNote("note-2"))
+	require.Nil(manager.UpdateNote("note-2", "Links to [[note-1]]"))
+
+	backlinks := manager.Backlinks("note-1")
+	require.Len(backlinks, 1)
+	require.Equal("note-2", backlinks[0].Filename)
+	require.Empty(manager.Backlinks("note-2"))
+}
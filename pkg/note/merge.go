@@ -0,0 +1,187 @@
+package note
+
+import "strings"
+
+// hunk represents a change from a base text, where the base lines in the
+// range [start, end) are replaced by lines
+type hunk struct {
+	start int      // First base line that is replaced
+	end   int      // Line after the last base line that is replaced
+	lines []string // Lines that replace the base lines
+}
+
+// Split text into lines, keeping line endings so the text can be rebuilt exactly
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	return strings.SplitAfter(text, "\n")
+}
+
+// Return the changes that turn the base lines into the other lines, using the
+// longest common subsequence of both
+func diffLines(base []string, other []string) []hunk {
+	// Skip the common prefix and suffix, which are usually most of a note
+	prefix := 0
+	for prefix < len(base) && prefix < len(other) && base[prefix] == other[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(base)-prefix && suffix < len(other)-prefix && base[len(base)-1-suffix] == other[len(other)-1-suffix] {
+		suffix++
+	}
+
+	a := base[prefix : len(base)-suffix]
+	b := other[prefix : len(other)-suffix]
+
+	// Compute the lengths of the longest common subsequences of every suffix
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	// Walk the common subsequence, recording every gap as a hunk
+	hunks := []hunk{}
+	i, j := 0, 0
+	startI, startJ := 0, 0
+
+	flush := func() {
+		if startI != i || startJ != j {
+			hunks = append(hunks, hunk{
+				start: prefix + startI,
+				end:   prefix + i,
+				lines: b[startJ:j],
+			})
+		}
+	}
+
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			flush()
+			i++
+			j++
+			startI, startJ = i, j
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			i++
+		} else {
+			j++
+		}
+	}
+	i, j = len(a), len(b)
+	flush()
+
+	return hunks
+}
+
+// Apply the provided hunks, which must fall within [start, end), to the base
+// lines in that range
+func applyHunks(base []string, start int, end int, hunks []hunk) []string {
+	result := []string{}
+
+	position := start
+	for _, h := range hunks {
+		result = append(result, base[position:h.start]...)
+		result = append(result, h.lines...)
+		position = h.end
+	}
+
+	return append(result, base[position:end]...)
+}
+
+// Return whether two slices of lines are equal
+func equalLines(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Merge3 performs a line-based three-way merge of two versions of a text that
+// were both changed from a common base. The merged text is returned along with
+// whether the merge succeeded. A merge fails when both versions change the
+// same or adjacent lines in different ways
+func Merge3(base string, local string, remote string) (string, bool) {
+	baseLines := splitLines(base)
+	localHunks := diffLines(baseLines, splitLines(local))
+	remoteHunks := diffLines(baseLines, splitLines(remote))
+
+	merged := []string{}
+	position := 0
+	l, r := 0, 0
+
+	for l < len(localHunks) || r < len(remoteHunks) {
+		// Start a group with the hunk that begins first
+		start, end := 0, 0
+		localGroup, remoteGroup := []hunk{}, []hunk{}
+
+		if r >= len(remoteHunks) || (l < len(localHunks) && localHunks[l].start <= remoteHunks[r].start) {
+			start, end = localHunks[l].start, localHunks[l].end
+			localGroup = append(localGroup, localHunks[l])
+			l++
+		} else {
+			start, end = remoteHunks[r].start, remoteHunks[r].end
+			remoteGroup = append(remoteGroup, remoteHunks[r])
+			r++
+		}
+
+		// Extend the group with every hunk that overlaps or touches it
+		for {
+			if l < len(localHunks) && localHunks[l].start <= end {
+				localGroup = append(localGroup, localHunks[l])
+				if localHunks[l].end > end {
+					end = localHunks[l].end
+				}
+				l++
+			} else if r < len(remoteHunks) && remoteHunks[r].start <= end {
+				remoteGroup = append(remoteGroup, remoteHunks[r])
+				if remoteHunks[r].end > end {
+					end = remoteHunks[r].end
+				}
+				r++
+			} else {
+				break
+			}
+		}
+
+		merged = append(merged, baseLines[position:start]...)
+		position = end
+
+		switch {
+		case len(remoteGroup) == 0:
+			merged = append(merged, applyHunks(baseLines, start, end, localGroup)...)
+		case len(localGroup) == 0:
+			merged = append(merged, applyHunks(baseLines, start, end, remoteGroup)...)
+		default:
+			// Both versions changed this region, which only merges if the changes agree
+			localVersion := applyHunks(baseLines, start, end, localGroup)
+			remoteVersion := applyHunks(baseLines, start, end, remoteGroup)
+			if !equalLines(localVersion, remoteVersion) {
+				return "", false
+			}
+			merged = append(merged, localVersion...)
+		}
+	}
+
+	merged = append(merged, baseLines[position:]...)
+	return strings.Join(merged, ""), true
+}
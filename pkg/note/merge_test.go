@@ -0,0 +1,43 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test merging edits to different parts of a note
+func TestMerge3(t *testing.T) {
+	require := require.New(t)
+
+	base := "# Title\n\none\ntwo\nthree\nfour\nfive\n"
+	local := "# Title\n\nONE\ntwo\nthree\nfour\nfive\n"
+	remote := "# Title\n\none\ntwo\nthree\nfour\nFIVE\nsix\n"
+
+	merged, ok := note.Merge3(base, local, remote)
+	require.True(ok)
+	require.Equal("# Title\n\nONE\ntwo\nthree\nfour\nFIVE\nsix\n", merged)
+
+	// Identical edits on both sides merge cleanly
+	merged, ok = note.Merge3(base, local, local)
+	require.True(ok)
+	require.Equal(local, merged)
+
+	// Edits on only one side are kept
+	merged, ok = note.Merge3(base, base, remote)
+	require.True(ok)
+	require.Equal(remote, merged)
+}
+
+// Test merging conflicting edits
+func TestMerge3Conflict(t *testing.T) {
+	require := require.New(t)
+
+	base := "# Title\n\none\ntwo\n"
+	local := "# Title\n\nlocal\ntwo\n"
+	remote := "# Title\n\nremote\ntwo\n"
+
+	_, ok := note.Merge3(base, local, remote)
+	require.False(ok)
+}
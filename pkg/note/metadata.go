@@ -4,8 +4,14 @@ import "time"
 
 // Metadata contains generic metadata for an note
 type Metadata struct {
-	Filename  string    `json:"filename"`  // Filename of the note (used to associate where the note is stored)
-	Author    string    `json:"author"`    // The author of the note
-	CreatedAt time.Time `json:"createdAt"` // Time the note was last created
-	UpdatedAt time.Time `json:"updatedAt"` // The the note was last updated
+	Filename  string    `json:"filename"`            // Filename of the note (used to associate where the note is stored)
+	Title     string    `json:"title,omitempty"`     // Human-readable title of the note, in any script
+	Author    string    `json:"author"`              // The author of the note
+	CreatedAt time.Time `json:"createdAt"`           // Time the note was last created
+	UpdatedAt time.Time `json:"updatedAt"`           // The the note was last updated
+	Encrypted bool      `json:"encrypted,omitempty"` // Whether the note is encrypted at rest
+
+	// Custom fields, such as 'status' or 'due'. The map is replaced rather
+	// than changed in place, so copies of the metadata keep their values
+	Fields map[string]any `json:"fields,omitempty"`
 }
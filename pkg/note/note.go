@@ -1,17 +1,34 @@
 package note
 
 import (
-	"fmt"
+	"bytes"
+	"html/template"
+	"log/slog"
 	"regexp"
 	"strings"
 	"time"
 
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
 )
 
-// Only allow a-z, A-Z, 0-9, '-', and '_' for valid note names
-var filenameMatcher = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+// Only allow letters, combining marks, and numbers of any script, '-', and '_'
+// for valid note names
+var filenameMatcher = regexp.MustCompile(`^[\p{L}\p{M}\p{N}_-]+$`)
+
+// Markdown renderer used to convert note content into HTML. Raw HTML in notes
+// is escaped so rendered notes are safe to serve
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Template used to wrap a note's rendered content with its metadata
+var htmlTemplate = template.Must(template.New("note").Parse(`<article class="note">
+<header class="note-metadata">
+<span class="note-author">{{.Note.Author}}</span>
+<time class="note-created" datetime="{{.Note.CreatedAt.Format "2006-01-02T15:04:05Z07:00"}}">{{.Note.CreatedAt.Format "2006-01-02"}}</time>
+<time class="note-updated" datetime="{{.Note.UpdatedAt.Format "2006-01-02T15:04:05Z07:00"}}">{{.Note.UpdatedAt.Format "2006-01-02"}}</time>
+</header>
+{{.Content}}</article>
+`))
 
 // Note defines a struct to contain and wrap a text file that a user
 // can edit. This note is stored as a markdown file with associated
@@ -20,23 +37,35 @@ var filenameMatcher = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 type Note struct {
 	Metadata        // Note Metadata
 	Content  string `json:"-"` // Note content (assumed to be markdown format)
+
+	unloaded bool // Whether the content still has to be read from the note's file
 }
 
 // Generate and return markdown representation of the note. The note
-// begins with yaml metadata and then contains markdown content after two
-// new lines
+// begins with yaml metadata, including registered front matter fields, and
+// then contains markdown content after two new lines
 func (a *Note) AsMarkdown() string {
 	output := ""
 
 	// Create yaml metadata at the top of the output
 	output += "---\n"
+	if a.Title != "" {
+		output += yamlLine("title", a.Title)
+	}
 	output += "author: " + a.Author + "\n"
 	output += "createdAt: " + a.CreatedAt.Format(time.RFC3339) + "\n"
 	output += "updatedAt: " + a.UpdatedAt.Format(time.RFC3339) + "\n"
+	output += a.fieldsFrontMatter()
+	output += extraFrontMatter(a)
 	output += "---\n\n"
 
-	// Add markdown content to the rest of the output
-	output += a.Content
+	// Add markdown content to the rest of the output, without any front matter
+	// of its own, which is merged into the metadata above
+	if frontMatter, body, err := ParseFrontMatter(a.Content); err == nil && frontMatter != nil {
+		output += strings.TrimLeft(body, "\n")
+	} else {
+		output += a.Content
+	}
 
 	return output
 }
@@ -45,28 +74,51 @@ func (a *Note) AsMarkdown() string {
 // metadata is rendered into a template, and then then note's markdown
 // content is rendered as HTML using an external tool
 func (a *Note) AsHTML() string {
-	// Not implemented
-	return ""
+	// Render the markdown content
+	content := bytes.Buffer{}
+	if err := markdown.Convert([]byte(a.Content), &content); err != nil {
+		slog.Error("failed to render note as HTML", "filename", a.Filename, "err", err)
+		return ""
+	}
+
+	// Render the metadata and content into the template
+	output := strings.Builder{}
+	err := htmlTemplate.Execute(&output, map[string]any{
+		"Note":    a,
+		"Content": template.HTML(content.String()),
+	})
+	if err != nil {
+		slog.Error("failed to render note as HTML", "filename", a.Filename, "err", err)
+		return ""
+	}
+
+	return output.String()
 }
 
-// Create a new note from a provided configuration and filename
+// Create a new note from a provided configuration and filename. The note's
+// title is made from the filename, capitalized in the config's title language
 func NewNote(config *Config, filename string) (*Note, error) {
+	return newNote(config, filename, "")
+}
+
+// Create a new note with a title, making the title from the filename if it is
+// empty
+func newNote(config *Config, filename string, title string) (*Note, error) {
 	// Make sure the filename is valid
 	if !filenameMatcher.MatchString(filename) {
-		return nil, fmt.Errorf("invalid name '%s'", filename)
+		return nil, wrapf(ErrInvalidName, "invalid name '%s'", filename)
 	}
 
 	// Generate note title
-	titleComponents := strings.Split(filename, "-")
-	title := ""
-	for _, t := range titleComponents {
-		title += cases.Title(language.English).String(t) + " "
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = config.titleFromFilename(filename)
 	}
-	title = strings.Trim(title, " ")
 
 	return &Note{
 		Metadata: Metadata{
 			Filename:  filename,
+			Title:     title,
 			Author:    config.DefaultAuthor,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
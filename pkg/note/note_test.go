@@ -54,16 +54,38 @@ func TestNoteAsMarkdown(t *testing.T) {
 	markdown := note.AsMarkdown()
 	lines := strings.Split(markdown, "\n")
 
-	require.Equal(9, len(lines))
+	require.Equal(10, len(lines))
 
 	// Check line equality
 	assert.Equal("---", lines[0])
-	assert.Equal("author: Ethan", lines[1])
-	assert.Equal("createdAt: "+note.CreatedAt.Format(time.RFC3339), lines[2])
-	assert.Equal("updatedAt: "+note.UpdatedAt.Format(time.RFC3339), lines[3])
-	assert.Equal("---", lines[4])
-	assert.Equal("", lines[5])
-	assert.Equal("# Test Note", lines[6])
-	assert.Equal("", lines[7])
+	assert.Equal("title: Test Note", lines[1])
+	assert.Equal("author: Ethan", lines[2])
+	assert.Equal("createdAt: "+note.CreatedAt.Format(time.RFC3339), lines[3])
+	assert.Equal("updatedAt: "+note.UpdatedAt.Format(time.RFC3339), lines[4])
+	assert.Equal("---", lines[5])
+	assert.Equal("", lines[6])
+	assert.Equal("# Test Note", lines[7])
 	assert.Equal("", lines[8])
+	assert.Equal("", lines[9])
+}
+
+// TestNoteAsHTML tests the generation of an note represented in HTML
+func TestNoteAsHTML(t *testing.T) {
+	// Setup testing objects
+	require := require.New(t)
+	config := noteTestSetup()
+
+	// Create a new note
+	note, err := note.NewNote(config, "test-note")
+	require.Nil(err)
+	note.Content += "Some *text* and <script>alert(1)</script>\n"
+
+	// Test HTML representation
+	html := note.AsHTML()
+
+	require.True(strings.HasPrefix(html, `<article class="note">`))
+	require.Contains(html, `<span class="note-author">Ethan</span>`)
+	require.Contains(html, "<h1>Test Note</h1>")
+	require.Contains(html, "<em>text</em>")
+	require.NotContains(html, "<script>")
 }
@@ -0,0 +1,155 @@
+package note
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// options holds the settings New builds a manager with
+type options struct {
+	configPath  string
+	managerPath string
+	directory   string
+	clock       func() time.Time
+	logger      *slog.Logger
+	store       Store
+	create      bool
+}
+
+// Option configures a manager created with New
+type Option func(*options)
+
+// Read the config from the provided path instead of the default config path
+func WithConfigPath(path string) Option {
+	return func(o *options) { o.configPath = path }
+}
+
+// Read note metadata from the provided path instead of the default manager path
+func WithManagerPath(path string) Option {
+	return func(o *options) { o.managerPath = path }
+}
+
+// Store notes in the provided directory, overriding the directory in the config
+// without changing the config file
+func WithDirectory(directory string) Option {
+	return func(o *options) { o.directory = directory }
+}
+
+// Use the provided function to get the current time, such as when notes are
+// created or updated
+func WithClock(clock func() time.Time) Option {
+	return func(o *options) { o.clock = clock }
+}
+
+// Write the manager's logs to the provided logger instead of the default logger.
+// Logs are also written to the log file in the config, if one is set
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// Read and write the manager's files through the provided store instead of the
+// filesystem
+func WithStore(store Store) Option {
+	return func(o *options) { o.store = store }
+}
+
+// Create the config file, manager file, and note directory if they don't exist
+func WithCreate() Option {
+	return func(o *options) { o.create = true }
+}
+
+// New returns a manager configured by the provided options. Paths that aren't
+// provided default to the paths of the vault in use. Missing files are treated
+// as empty, and are only created on the first save unless WithCreate is used
+func New(opts ...Option) (*Manager, error) {
+	o := &options{
+		configPath:  configPath,
+		managerPath: managerPath,
+		clock:       time.Now,
+		logger:      slog.Default(),
+		store:       OSStore{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.configPath == "" || o.managerPath == "" {
+		return nil, wrapf(ErrInvalidConfig, "config and manager paths are required when the home directory is unknown")
+	}
+
+	manager := &Manager{
+		Notes:       []*Note{},
+		configPath:  o.configPath,
+		managerPath: o.managerPath,
+		clock:       o.clock,
+		logger:      o.logger,
+		store:       o.store,
+		directory:   o.directory,
+	}
+	manager.attachConfig(NewConfig())
+
+	// Load the config file if it exists
+	if _, err := o.store.Stat(o.configPath); errors.Is(err, os.ErrNotExist) {
+		manager.log().Debug("config file does not exist, using defaults")
+	} else if err := manager.Config.Load(); err != nil {
+		manager.log().Error("failed to load config", "err", err)
+		return nil, err
+	}
+
+	// Write logs to the log file in the config as well, if one is set
+	logger, err := manager.Config.withLogFile(manager.log())
+	if err != nil {
+		manager.log().Error("failed to open log file", "err", err)
+		return nil, err
+	}
+	manager.logger = logger
+	manager.attachConfig(manager.Config)
+
+	if manager.Directory() == "" {
+		return nil, wrapf(ErrInvalidConfig, "a note directory is required when the home directory is unknown")
+	}
+
+	// Load the manager file if it exists
+	if _, err := o.store.Stat(o.managerPath); errors.Is(err, os.ErrNotExist) {
+		manager.log().Debug("manager file does not exist, starting without notes")
+	} else if err := manager.Load(); err != nil {
+		manager.log().Error("failed to load manager", "err", err)
+		return nil, err
+	}
+
+	if !o.create {
+		return manager, nil
+	}
+
+	// Create the files that don't exist yet
+	if _, err := o.store.Stat(o.configPath); errors.Is(err, os.ErrNotExist) {
+		manager.log().Debug("creating config file")
+
+		if err := manager.Config.Save(); err != nil {
+			manager.log().Error("failed to save config", "err", err)
+			return nil, err
+		}
+	}
+
+	if _, err := o.store.Stat(o.managerPath); errors.Is(err, os.ErrNotExist) {
+		manager.log().Debug("creating manager file")
+
+		if err := manager.Save(); err != nil {
+			manager.log().Error("failed to create manager file", "err", err)
+			return nil, err
+		}
+	}
+
+	if _, err := o.store.Stat(manager.Directory()); errors.Is(err, os.ErrNotExist) {
+		manager.log().Debug("note directory does not exist, creating it")
+
+		if err := o.store.MkdirAll(manager.Directory(), 0755); err != nil {
+			manager.log().Error("failed to create note directory", "err", err)
+			return nil, err
+		}
+	}
+
+	return manager, nil
+}
@@ -0,0 +1,178 @@
+package note_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that managers created without WithCreate don't create files until saved
+func TestNewWithoutCreate(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	root := t.TempDir()
+
+	manager, err := note.New(
+		note.WithConfigPath(path.Join(root, "config.json")),
+		note.WithManagerPath(path.Join(root, "manager.json")),
+		note.WithDirectory(path.Join(root, "entries")),
+	)
+	require.Nil(err)
+	require.Empty(manager.GetNotes())
+	require.Equal(path.Join(root, "entries"), manager.Directory())
+
+	entries, err := os.ReadDir(root)
+	require.Nil(err)
+	require.Empty(entries)
+
+	// Creating a note creates the manager file and note file, but not the config file
+	require.Nil(manager.CreateNote("note-1"))
+
+	for _, file := range []string{"manager.json", "entries/note-1.md"} {
+		_, err := os.Stat(path.Join(root, file))
+		require.Nil(err)
+	}
+	_, err = os.Stat(path.Join(root, "config.json"))
+	require.True(errors.Is(err, os.ErrNotExist))
+
+	// The note is loaded by a new manager
+	manager, err = note.New(
+		note.WithConfigPath(path.Join(root, "config.json")),
+		note.WithManagerPath(path.Join(root, "manager.json")),
+		note.WithDirectory(path.Join(root, "entries")),
+	)
+	require.Nil(err)
+	require.NotNil(manager.GetNote("note-1"))
+}
+
+// Test that the directory passed to New is not written to the config file
+func TestWithDirectoryKeepsConfig(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	store := &note.MemoryStore{}
+	require.Nil(store.MkdirAll("/vault", 0755))
+	config := []byte(`{"directory": "/home/notes", "editor": "true", "default_author": "Ethan"}`)
+	require.Nil(store.WriteFile("/vault/config.json", config, 0600))
+
+	manager, err := note.New(
+		note.WithConfigPath("/vault/config.json"),
+		note.WithManagerPath("/vault/manager.json"),
+		note.WithDirectory("/tmp/entries"),
+		note.WithStore(store),
+	)
+	require.Nil(err)
+	require.Equal("/tmp/entries", manager.Directory())
+	require.Equal("/home/notes", manager.Config.Directory)
+
+	require.Nil(manager.CreateNote("note-1"))
+	require.Nil(manager.SetFields("note-1", map[string]any{"status": "draft"}))
+
+	content, err := store.ReadFile("/vault/config.json")
+	require.Nil(err)
+	require.Equal(config, content)
+	_, err = store.Stat("/tmp/entries/note-1.md")
+	require.Nil(err)
+
+	// Paths are required without a home directory
+	_, err = note.New(note.WithConfigPath(""))
+	require.True(errors.Is(err, note.ErrInvalidConfig))
+}
+
+// Test that WithCreate creates missing files
+func TestNewWithCreate(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	root := t.TempDir()
+
+	_, err := note.New(
+		note.WithConfigPath(path.Join(root, "config.json")),
+		note.WithManagerPath(path.Join(root, "manager.json")),
+		note.WithDirectory(path.Join(root, "entries")),
+		note.WithCreate(),
+	)
+	require.Nil(err)
+
+	for _, file := range []string{"config.json", "manager.json", "entries"} {
+		_, err := os.Stat(path.Join(root, file))
+		require.Nil(err)
+	}
+}
+
+// Test managers that store their files in memory, in parallel
+func TestNewMemoryStore(t *testing.T) {
+	for _, name := range []string{"first", "second", "third"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Setup test
+			require := require.New(t)
+			store := &note.MemoryStore{}
+			now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			logs := bytes.Buffer{}
+
+			manager, err := note.New(
+				note.WithConfigPath("/vault/config.json"),
+				note.WithManagerPath("/vault/manager.json"),
+				note.WithDirectory("/vault/entries"),
+				note.WithStore(store),
+				note.WithClock(func() time.Time { return now }),
+				note.WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+			)
+			require.Nil(err)
+
+			// Create and update a note
+			require.Nil(manager.CreateNote(name))
+			require.Equal(now, manager.GetNote(name).CreatedAt)
+
+			now = now.Add(time.Hour)
+			require.Nil(manager.UpdateNote(name, "# Updated\n"))
+			require.Equal(now, manager.GetNote(name).UpdatedAt)
+
+			require.Equal([]string{"/vault/entries/" + name + ".md", "/vault/manager.json"}, store.Files())
+			require.Contains(logs.String(), "msg=\"creating new note\" filename="+name)
+
+			// The note is loaded from the store by a new manager
+			manager, err = note.New(
+				note.WithConfigPath("/vault/config.json"),
+				note.WithManagerPath("/vault/manager.json"),
+				note.WithDirectory("/vault/entries"),
+				note.WithStore(store),
+			)
+			require.Nil(err)
+			require.Equal("/vault/entries", manager.Directory())
+
+			content, err := manager.ReadNote(name)
+			require.Nil(err)
+			require.Equal("# Updated\n", content)
+
+			// Deleting the note removes its file
+			require.Nil(manager.DeleteNote(name))
+			_, err = store.Stat("/vault/entries/" + name + ".md")
+			require.True(errors.Is(err, os.ErrNotExist))
+		})
+	}
+}
+
+// Test that GetManager creates missing files
+func TestGetManagerCreates(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	_, err := managerTestSetup()
+	require.Nil(err)
+
+	wd, err := os.Getwd()
+	require.Nil(err)
+
+	for _, file := range []string{"config.json", "manager.json", "entries"} {
+		_, err := os.Stat(path.Join(wd, "testing/dirty", file))
+		require.Nil(err)
+	}
+}
@@ -0,0 +1,184 @@
+package note
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortField is a value notes can be sorted by
+type SortField string
+
+const (
+	SortCreated SortField = "created" // Sort by creation time
+	SortUpdated SortField = "updated" // Sort by last update time
+	SortName    SortField = "name"    // Sort by filename
+	SortSize    SortField = "size"    // Sort by content size
+)
+
+// DateField is a time of a note that notes can be filtered by
+type DateField string
+
+const (
+	DateCreated DateField = "created" // Filter by creation time
+	DateUpdated DateField = "updated" // Filter by last update time
+)
+
+// ListOptions describes how to filter, sort, and page a list of notes. The
+// zero value lists every note in insertion order
+type ListOptions struct {
+	Sort    SortField // Field to sort notes by, or insertion order if empty
+	Reverse bool      // Whether to reverse the order of the notes
+	Author  string    // Only include notes by this author (case-insensitive)
+	Date    DateField // Time compared with Since and Until, which defaults to the creation time
+	Since   time.Time // Only include notes at or after this time
+	Until   time.Time // Only include notes before this time
+	Where   string    // Only include notes matching a condition, such as 'status=draft AND priority>2'
+	Offset  int       // Number of notes to skip
+	Limit   int       // Maximum number of notes to return, or all notes if zero
+}
+
+// Validate the options, returning an error for unknown sort or date fields,
+// negative paging values, and invalid where conditions
+func (o ListOptions) Validate() error {
+	switch o.Sort {
+	case "", SortCreated, SortUpdated, SortName, SortSize:
+	default:
+		return wrapf(ErrInvalidQuery, "invalid sort field '%s'", o.Sort)
+	}
+
+	switch o.Date {
+	case "", DateCreated, DateUpdated:
+	default:
+		return wrapf(ErrInvalidQuery, "invalid date field '%s'", o.Date)
+	}
+
+	if o.Offset < 0 || o.Limit < 0 {
+		return wrapf(ErrInvalidQuery, "offset and limit cannot be negative")
+	}
+
+	if !o.Since.IsZero() && !o.Until.IsZero() && o.Until.Before(o.Since) {
+		return wrapf(ErrInvalidQuery, "until cannot be before since")
+	}
+
+	if _, err := ParseWhere(o.Where); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Return the time of a note that the options filter by
+func (o ListOptions) date(note *Note) time.Time {
+	if o.Date == DateUpdated {
+		return note.UpdatedAt
+	}
+
+	return note.CreatedAt
+}
+
+// Return whether a note passes the options' filters
+func (o ListOptions) matches(note *Note) bool {
+	if o.Author != "" && !strings.EqualFold(note.Author, o.Author) {
+		return false
+	}
+
+	date := o.date(note)
+	if !o.Since.IsZero() && date.Before(o.Since) {
+		return false
+	}
+	if !o.Until.IsZero() && !date.Before(o.Until) {
+		return false
+	}
+
+	return true
+}
+
+// Return whether note a sorts before note b by the options' sort field
+func (o ListOptions) less(a *Note, b *Note) bool {
+	switch o.Sort {
+	case SortCreated:
+		return a.CreatedAt.Before(b.CreatedAt)
+	case SortUpdated:
+		return a.UpdatedAt.Before(b.UpdatedAt)
+	case SortName:
+		return a.Filename < b.Filename
+	case SortSize:
+		return a.Size() < b.Size()
+	}
+
+	return false
+}
+
+// Return the size of the note's content in bytes. The content of encrypted
+// notes and notes from ListNotes is not loaded, so their size is zero
+func (a *Note) Size() int {
+	return len(a.Content)
+}
+
+// Return the number of words in the note's content
+func (a *Note) WordCount() int {
+	return len(strings.Fields(a.Content))
+}
+
+// Return the notes in the manager that match the provided options, sorted and
+// paged as requested. The returned slice can be modified without changing the
+// manager. The content of the notes is only loaded when sorting by size, so
+// use LoadContent before reading it
+func (m *Manager) ListNotes(opts ListOptions) ([]*Note, error) {
+	m.log().Debug("listing notes", "options", opts)
+
+	if err := opts.Validate(); err != nil {
+		m.log().Error("invalid list options", "err", err)
+		return nil, err
+	}
+
+	where, err := ParseWhere(opts.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	// Conditions on tags, links, and text need the content of every note
+	if where.needsContent() {
+		m.loadAll()
+	}
+
+	// Filter the notes
+	notes := []*Note{}
+	for _, note := range m.Notes {
+		if opts.matches(note) && where.Matches(note) {
+			notes = append(notes, note)
+		}
+	}
+
+	// Sizes need the content of the notes
+	if opts.Sort == SortSize {
+		if err := m.LoadContent(notes); err != nil {
+			return nil, err
+		}
+	}
+
+	// Sort the notes, keeping insertion order for equal values
+	if opts.Sort != "" {
+		sort.SliceStable(notes, func(i, j int) bool {
+			return opts.less(notes[i], notes[j])
+		})
+	}
+	if opts.Reverse {
+		for i, j := 0, len(notes)-1; i < j; i, j = i+1, j-1 {
+			notes[i], notes[j] = notes[j], notes[i]
+		}
+	}
+
+	// Page the notes
+	if opts.Offset >= len(notes) {
+		return []*Note{}, nil
+	}
+	notes = notes[opts.Offset:]
+
+	if opts.Limit > 0 && opts.Limit < len(notes) {
+		notes = notes[:opts.Limit]
+	}
+
+	return notes, nil
+}
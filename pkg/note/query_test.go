@@ -0,0 +1,106 @@
+package note_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper function to return the filenames of notes
+func filenames(notes []*note.Note) []string {
+	names := []string{}
+	for _, n := range notes {
+		names = append(names, n.Filename)
+	}
+
+	return names
+}
+
+// Setup notes with known times, authors, and content for list tests
+func queryTestSetup(t *testing.T) *note.Manager {
+	manager, err := managerTestSetup()
+	require.Nil(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"bravo", "alpha", "charlie"} {
+		require.Nil(t, manager.CreateNote(name))
+
+		n := manager.GetNote(name)
+		n.CreatedAt = start.AddDate(0, 0, i)
+		n.UpdatedAt = start.AddDate(0, 0, 10-i)
+	}
+
+	manager.GetNote("alpha").Author = "Someone"
+	require.Nil(t, manager.UpdateNote("charlie", "one two three four"))
+	manager.GetNote("charlie").UpdatedAt = start.AddDate(0, 0, 8)
+
+	return manager
+}
+
+// Test sorting notes
+func TestListNotesSort(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager := queryTestSetup(t)
+
+	for _, test := range []struct {
+		opts     note.ListOptions
+		expected []string
+	}{
+		{note.ListOptions{}, []string{"bravo", "alpha", "charlie"}},
+		{note.ListOptions{Sort: note.SortName}, []string{"alpha", "bravo", "charlie"}},
+		{note.ListOptions{Sort: note.SortCreated, Reverse: true}, []string{"charlie", "alpha", "bravo"}},
+		{note.ListOptions{Sort: note.SortUpdated}, []string{"charlie", "alpha", "bravo"}},
+		{note.ListOptions{Sort: note.SortSize, Reverse: true}, []string{"charlie", "alpha", "bravo"}},
+	} {
+		notes, err := manager.ListNotes(test.opts)
+		require.Nil(err)
+		require.Equal(test.expected, filenames(notes))
+	}
+
+	// The manager's order is not changed
+	require.Equal([]string{"bravo", "alpha", "charlie"}, filenames(manager.GetNotes()))
+}
+
+// Test filtering and paging notes
+func TestListNotesFilter(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager := queryTestSetup(t)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		opts     note.ListOptions
+		expected []string
+	}{
+		{note.ListOptions{Author: "someone"}, []string{"alpha"}},
+		{note.ListOptions{Since: start.AddDate(0, 0, 1)}, []string{"alpha", "charlie"}},
+		{note.ListOptions{Until: start.AddDate(0, 0, 1)}, []string{"bravo"}},
+		{note.ListOptions{Date: note.DateUpdated, Since: start.AddDate(0, 0, 9)}, []string{"bravo", "alpha"}},
+		{note.ListOptions{Sort: note.SortName, Offset: 1, Limit: 1}, []string{"bravo"}},
+		{note.ListOptions{Offset: 5}, []string{}},
+	} {
+		notes, err := manager.ListNotes(test.opts)
+		require.Nil(err)
+		require.Equal(test.expected, filenames(notes))
+	}
+
+	// Invalid options are rejected
+	_, err := manager.ListNotes(note.ListOptions{Sort: "color"})
+	require.True(errors.Is(err, note.ErrInvalidQuery))
+	_, err = manager.ListNotes(note.ListOptions{Limit: -1})
+	require.True(errors.Is(err, note.ErrInvalidQuery))
+}
+
+// Test counting the words in a note
+func TestNoteWordCount(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager := queryTestSetup(t)
+
+	require.Equal(4, manager.GetNote("charlie").WordCount())
+	require.Equal(2, manager.GetNote("alpha").WordCount())
+}
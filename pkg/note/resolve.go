@@ -0,0 +1,115 @@
+package note
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// How closely a note's filename matches a query, from best to worst
+const (
+	matchExact = iota
+	matchPrefix
+	matchSubstring
+	matchFuzzy
+	matchNone
+)
+
+// AmbiguousError is returned when a query matches several notes equally well
+type AmbiguousError struct {
+	Query   string   // Query that was resolved
+	Matches []string // Filenames of the matching notes, best first
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("'%s' matches multiple notes: %s", e.Query, strings.Join(e.Matches, ", "))
+}
+
+func (e *AmbiguousError) Is(target error) bool { return target == ErrAmbiguous }
+
+// Return how closely a filename matches a lowercase query
+func matchFilename(filename string, query string) int {
+	switch {
+	case filename == query:
+		return matchExact
+	case strings.HasPrefix(filename, query):
+		return matchPrefix
+	case strings.Contains(filename, query):
+		return matchSubstring
+	}
+
+	// Every character of the query must appear in the filename in order
+	remaining := filename
+	for _, r := range query {
+		index := strings.IndexRune(remaining, r)
+		if index < 0 {
+			return matchNone
+		}
+		remaining = remaining[index+len(string(r)):]
+	}
+
+	return matchFuzzy
+}
+
+// Return the notes whose filenames best match the query. Exact matches, which
+// include notes whose title is the query, are preferred over prefix matches,
+// prefix matches over substring matches, and substring matches over fuzzy
+// matches, where the query's characters appear in order. Only notes from the
+// best kind of match are returned, shortest filename first
+func (m *Manager) MatchNotes(query string) []*Note {
+	m.log().Debug("matching notes", "query", query)
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return []*Note{}
+	}
+
+	best := matchNone
+	matches := []*Note{}
+	for _, note := range m.Notes {
+		match := matchFilename(note.Filename, query)
+		if strings.EqualFold(note.Title, query) {
+			match = matchExact
+		}
+		if match < best {
+			best = match
+			matches = []*Note{}
+		}
+		if match == best && match != matchNone {
+			matches = append(matches, note)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if len(matches[i].Filename) != len(matches[j].Filename) {
+			return len(matches[i].Filename) < len(matches[j].Filename)
+		}
+		return matches[i].Filename < matches[j].Filename
+	})
+
+	return matches
+}
+
+// Resolve a query to the filename of a single note using MatchNotes. An
+// *AmbiguousError is returned if several notes match equally well
+func (m *Manager) ResolveNote(query string) (string, error) {
+	m.log().Debug("resolving note name", "query", query)
+
+	matches := m.MatchNotes(query)
+	switch len(matches) {
+	case 0:
+		m.log().Error("no note matches", "query", query)
+		return "", wrapf(ErrNotFound, "note with name '%s' not found", strings.ToLower(query))
+	case 1:
+		m.log().Debug("resolved note name", "query", query, "match", matches[0].Filename)
+		return matches[0].Filename, nil
+	}
+
+	filenames := []string{}
+	for _, note := range matches {
+		filenames = append(filenames, note.Filename)
+	}
+
+	m.log().Error("matches multiple notes", "query", query)
+	return "", &AmbiguousError{Query: query, Matches: filenames}
+}
@@ -0,0 +1,44 @@
+package note_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test resolving note names with exact, prefix, substring, and fuzzy matches
+func TestResolveNote(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+
+	for _, name := range []string{"2026-10-18-standup", "2026-10-19-retro", "groceries", "go", "golang-notes"} {
+		require.Nil(manager.CreateNote(name))
+	}
+
+	for query, expected := range map[string]string{
+		"go":      "go",                 // Exact matches win over prefix matches
+		"GROC":    "groceries",          // Prefix matches ignore case
+		"standup": "2026-10-18-standup", // Substring match
+		"retr":    "2026-10-19-retro",   // Substring match
+		"glngn":   "golang-notes",       // Fuzzy match
+	} {
+		filename, err := manager.ResolveNote(query)
+		require.Nil(err, query)
+		require.Equal(expected, filename, query)
+	}
+
+	// Several equally good matches are ambiguous
+	_, err = manager.ResolveNote("2026")
+	ambiguous := &note.AmbiguousError{}
+	require.True(errors.As(err, &ambiguous))
+	require.Equal([]string{"2026-10-19-retro", "2026-10-18-standup"}, ambiguous.Matches)
+
+	// No match
+	_, err = manager.ResolveNote("xyz")
+	require.NotNil(err)
+	require.Equal("note with name 'xyz' not found", err.Error())
+}
@@ -0,0 +1,440 @@
+package note
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// FieldKind is the type of value a configuration field holds
+type FieldKind string
+
+const (
+	KindString    FieldKind = "string"    // Any text
+	KindInt       FieldKind = "int"       // A non-negative integer
+	KindDirectory FieldKind = "directory" // A directory that can be written to
+	KindFile      FieldKind = "file"      // A file in a directory that can be written to
+	KindCommand   FieldKind = "command"   // A command whose program is on PATH
+	KindEnum      FieldKind = "enum"      // One of a fixed set of values
+	KindLanguage  FieldKind = "language"  // A BCP 47 language tag, such as 'en' or 'nl-BE'
+	KindQuery     FieldKind = "query"     // A query written in the query language
+)
+
+// ConfigField declares a single configuration key, its type, and how it is
+// read from and written to a Config
+type ConfigField struct {
+	Key         string    // Key of the field in the configuration file
+	Description string    // Short description of the field
+	Kind        FieldKind // Type of value the field holds
+	Values      []string  // Allowed values of enum fields
+	Optional    bool      // Whether the field can be empty
+	Secret      bool      // Whether the value should be hidden when listed
+
+	get func(c *Config) string
+	set func(c *Config, value string)
+}
+
+// ConfigSchema declares every configuration field in the order they are listed
+var ConfigSchema = []ConfigField{
+	{
+		Key:         "directory",
+		Description: "directory where notes are stored",
+		Kind:        KindDirectory,
+		get:         func(c *Config) string { return c.Directory },
+		set:         func(c *Config, value string) { c.Directory = value },
+	},
+	{
+		Key:         "editor",
+		Description: "command used to open notes, with optional {file} and {line} placeholders (defaults to $VISUAL, $EDITOR, then vi)",
+		Kind:        KindCommand,
+		Optional:    true,
+		get:         func(c *Config) string { return c.Editor },
+		set:         func(c *Config, value string) { c.Editor = value },
+	},
+	{
+		Key:         "default_author",
+		Description: "author of new notes",
+		Kind:        KindString,
+		get:         func(c *Config) string { return c.DefaultAuthor },
+		set:         func(c *Config, value string) { c.DefaultAuthor = value },
+	},
+	{
+		Key:         "title_language",
+		Description: "language used to capitalize titles made from filenames, such as 'nl' or 'tr' (defaults to en)",
+		Kind:        KindLanguage,
+		Optional:    true,
+		get:         func(c *Config) string { return c.TitleLanguage },
+		set:         func(c *Config, value string) { c.TitleLanguage = value },
+	},
+	{
+		Key:         "backup_directory",
+		Description: "directory where automatic backups are written",
+		Kind:        KindDirectory,
+		Optional:    true,
+		get:         func(c *Config) string { return c.BackupDirectory },
+		set:         func(c *Config, value string) { c.BackupDirectory = value },
+	},
+	{
+		Key:         "backup_keep",
+		Description: "number of automatic backups to keep (0 keeps all backups)",
+		Kind:        KindInt,
+		Optional:    true,
+		get:         func(c *Config) string { return strconv.Itoa(c.BackupKeep) },
+		set: func(c *Config, value string) {
+			c.BackupKeep, _ = strconv.Atoi(value)
+		},
+	},
+	{
+		Key:         "api_token",
+		Description: "bearer token required by the HTTP API (empty disables authentication)",
+		Kind:        KindString,
+		Optional:    true,
+		Secret:      true,
+		get:         func(c *Config) string { return c.APIToken },
+		set:         func(c *Config, value string) { c.APIToken = value },
+	},
+	{
+		Key:         "publish_directory",
+		Description: "directory where notes published through the HTTP API are written (empty disables publishing)",
+		Kind:        KindDirectory,
+		Optional:    true,
+		get:         func(c *Config) string { return c.PublishDirectory },
+		set:         func(c *Config, value string) { c.PublishDirectory = value },
+	},
+	{
+		Key:         "log_file",
+		Description: "file where logs are written (empty disables the log file)",
+		Kind:        KindFile,
+		Optional:    true,
+		get:         func(c *Config) string { return c.LogFile },
+		set:         func(c *Config, value string) { c.LogFile = value },
+	},
+	{
+		Key:         "log_level",
+		Description: "minimum level of logs written to the log file (defaults to info)",
+		Kind:        KindEnum,
+		Values:      LogLevels,
+		Optional:    true,
+		get:         func(c *Config) string { return c.LogLevel },
+		set:         func(c *Config, value string) { c.LogLevel = value },
+	},
+	{
+		Key:         "log_max_size",
+		Description: "size in kilobytes after which the log file is rotated (0 uses 1024)",
+		Kind:        KindInt,
+		Optional:    true,
+		get:         func(c *Config) string { return strconv.Itoa(c.LogMaxSize) },
+		set: func(c *Config, value string) {
+			c.LogMaxSize, _ = strconv.Atoi(value)
+		},
+	},
+	{
+		Key:         "log_keep",
+		Description: "number of rotated log files to keep (0 keeps 3)",
+		Kind:        KindInt,
+		Optional:    true,
+		get:         func(c *Config) string { return strconv.Itoa(c.LogKeep) },
+		set: func(c *Config, value string) {
+			c.LogKeep, _ = strconv.Atoi(value)
+		},
+	},
+}
+
+// Prefix of keys that set the editor for an extension, such as 'editors.json'
+const editorsPrefix = "editors."
+
+// Return the field that sets the editor for files with an extension
+func editorField(extension string) ConfigField {
+	return ConfigField{
+		Key:         editorsPrefix + extension,
+		Description: "command used to open ." + extension + " files",
+		Kind:        KindCommand,
+		Optional:    true,
+		get:         func(c *Config) string { return c.Editors[extension] },
+		set: func(c *Config, value string) {
+			if value == "" {
+				delete(c.Editors, extension)
+				return
+			}
+			if c.Editors == nil {
+				c.Editors = map[string]string{}
+			}
+			c.Editors[extension] = value
+		},
+	}
+}
+
+// Prefix of keys that set the command run by a hook, such as 'hooks.post_edit'
+const hooksPrefix = "hooks."
+
+// Return the field that sets the command run by a hook
+func hookField(event HookEvent) ConfigField {
+	when, action, _ := strings.Cut(string(event), "_")
+	if when == "pre" {
+		when = "before"
+	} else {
+		when = "after"
+	}
+
+	return ConfigField{
+		Key:         hooksPrefix + string(event),
+		Description: "command run " + when + " a note is " + strings.TrimSuffix(action, "e") + "ed",
+		Kind:        KindCommand,
+		Optional:    true,
+		get:         func(c *Config) string { return c.Hooks[event].String() },
+		set: func(c *Config, value string) {
+			words, _ := SplitCommand(value)
+			if len(words) == 0 {
+				delete(c.Hooks, event)
+				return
+			}
+			if c.Hooks == nil {
+				c.Hooks = map[HookEvent]HookCommand{}
+			}
+			c.Hooks[event] = words
+		},
+	}
+}
+
+// Prefix of keys that save a search, such as 'queries.drafts'
+const queriesPrefix = "queries."
+
+// Regex to verify names of saved searches
+var queryNameMatcher = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// Return the field that saves a search under a name
+func queryField(name string) ConfigField {
+	return ConfigField{
+		Key:         queriesPrefix + name,
+		Description: "saved search run with 'note q " + name + "'",
+		Kind:        KindQuery,
+		Optional:    true,
+		get:         func(c *Config) string { return c.Queries[name] },
+		set: func(c *Config, value string) {
+			if value == "" {
+				delete(c.Queries, name)
+				return
+			}
+			if c.Queries == nil {
+				c.Queries = map[string]string{}
+			}
+			c.Queries[name] = value
+		},
+	}
+}
+
+// Return the schema of the field with the provided key
+func LookupConfigField(key string) (ConfigField, error) {
+	for _, field := range ConfigSchema {
+		if field.Key == key {
+			return field, nil
+		}
+	}
+
+	if extension, ok := strings.CutPrefix(key, editorsPrefix); ok && extension != "" && !strings.ContainsAny(extension, "./ ") {
+		return editorField(extension), nil
+	}
+
+	if name, ok := strings.CutPrefix(key, queriesPrefix); ok && queryNameMatcher.MatchString(name) {
+		return queryField(name), nil
+	}
+
+	if event, ok := strings.CutPrefix(key, hooksPrefix); ok {
+		for _, e := range HookEvents {
+			if string(e) == event {
+				return hookField(e), nil
+			}
+		}
+	}
+
+	return ConfigField{}, wrapf(ErrInvalidConfig, "unknown config key '%s'", key)
+}
+
+// Return the fields of the configuration, including the editors set for
+// extensions, the hooks that are set, and the saved searches, in the order
+// they are listed
+func (c *Config) Fields() []ConfigField {
+	fields := append([]ConfigField{}, ConfigSchema...)
+
+	extensions := []string{}
+	for extension := range c.Editors {
+		extensions = append(extensions, extension)
+	}
+	sort.Strings(extensions)
+
+	for _, extension := range extensions {
+		fields = append(fields, editorField(extension))
+	}
+
+	for _, event := range HookEvents {
+		if len(c.Hooks[event]) > 0 {
+			fields = append(fields, hookField(event))
+		}
+	}
+
+	names := []string{}
+	for name := range c.Queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fields = append(fields, queryField(name))
+	}
+
+	return fields
+}
+
+// Check that a directory can be written to. Directories that don't exist yet
+// are valid if they can be created
+func checkWritableDirectory(directory string) error {
+	// Find the closest directory that exists
+	existing := directory
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("'%s' is not a directory", existing)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+
+		parent := path.Dir(existing)
+		if parent == existing {
+			return fmt.Errorf("'%s' does not exist", directory)
+		}
+		existing = parent
+	}
+
+	// Try to create a file in it
+	file, err := os.CreateTemp(existing, ".note-write-check-*")
+	if err != nil {
+		return fmt.Errorf("'%s' is not writable", existing)
+	}
+	file.Close()
+	os.Remove(file.Name())
+
+	return nil
+}
+
+// Return whether a command is a relative path like './lint.sh', which is
+// resolved in the directory the command runs in rather than in PATH
+func isRelativePath(command string) bool {
+	return strings.Contains(command, "/") && !path.IsAbs(command)
+}
+
+// Validate a value for the field, returning an error that names the field
+func (f ConfigField) Validate(value string) error {
+	if value == "" {
+		if f.Optional {
+			return nil
+		}
+		return wrapf(ErrInvalidConfig, "invalid value for '%s' (value cannot be empty)", f.Key)
+	}
+
+	var err error
+	switch f.Kind {
+	case KindInt:
+		if n, convErr := strconv.Atoi(value); convErr != nil || n < 0 {
+			err = fmt.Errorf("'%s' is not a non-negative integer", value)
+		}
+
+	case KindDirectory:
+		if !path.IsAbs(value) {
+			err = fmt.Errorf("'%s' is not an absolute path", value)
+		} else {
+			err = checkWritableDirectory(value)
+		}
+
+	case KindFile:
+		if !path.IsAbs(value) {
+			err = fmt.Errorf("'%s' is not an absolute path", value)
+		} else if info, statErr := os.Stat(value); statErr == nil && info.IsDir() {
+			err = fmt.Errorf("'%s' is a directory", value)
+		} else {
+			err = checkWritableDirectory(path.Dir(value))
+		}
+
+	case KindCommand:
+		words, splitErr := SplitCommand(value)
+		if splitErr != nil {
+			err = splitErr
+		} else if len(words) == 0 {
+			err = fmt.Errorf("command is empty")
+		} else if _, lookErr := exec.LookPath(words[0]); lookErr != nil && !isRelativePath(words[0]) {
+			err = fmt.Errorf("'%s' was not found in PATH", words[0])
+		}
+
+	case KindLanguage:
+		if _, parseErr := language.Parse(value); parseErr != nil {
+			err = fmt.Errorf("'%s' is not a language tag", value)
+		}
+
+	case KindQuery:
+		if _, parseErr := ParseQuery(value); parseErr != nil {
+			err = parseErr
+		}
+
+	case KindEnum:
+		valid := false
+		for _, allowed := range f.Values {
+			valid = valid || value == allowed
+		}
+		if !valid {
+			err = fmt.Errorf("'%s' is not one of %s", value, strings.Join(f.Values, ", "))
+		}
+	}
+
+	if err != nil {
+		return wrapf(ErrInvalidConfig, "invalid value for '%s' (%v)", f.Key, err)
+	}
+
+	return nil
+}
+
+// Return the value of the configuration field with the provided key
+func (c *Config) Get(key string) (string, error) {
+	field, err := LookupConfigField(key)
+	if err != nil {
+		return "", err
+	}
+
+	return field.get(c), nil
+}
+
+// Validate and set the value of the configuration field with the provided key.
+// The configuration is not saved
+func (c *Config) Set(key string, value string) error {
+	field, err := LookupConfigField(key)
+	if err != nil {
+		return err
+	}
+
+	if err := field.Validate(value); err != nil {
+		return err
+	}
+
+	field.set(c, value)
+	return nil
+}
+
+// Validate every field of the configuration, returning the first error
+func (c *Config) Validate() error {
+	for _, field := range c.Fields() {
+		if err := field.Validate(field.get(c)); err != nil {
+			return err
+		}
+	}
+
+	return c.validateNoteFields()
+}
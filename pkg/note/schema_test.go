@@ -0,0 +1,74 @@
+package note_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test reading and writing configuration fields
+func TestConfigGetSet(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+
+	value, err := manager.Config.Get("default_author")
+	require.Nil(err)
+	require.Equal("Ethan", value)
+
+	require.Nil(manager.Config.Set("default_author", "Someone"))
+	require.Equal("Someone", manager.Config.DefaultAuthor)
+
+	require.Nil(manager.Config.Set("backup_keep", "3"))
+	require.Equal(3, manager.Config.BackupKeep)
+
+	// Optional fields can be cleared
+	require.Nil(manager.Config.Set("api_token", "secret"))
+	require.Nil(manager.Config.Set("api_token", ""))
+	require.Equal("", manager.Config.APIToken)
+
+	// Unknown keys are rejected
+	_, err = manager.Config.Get("colour")
+	require.Equal("unknown config key 'colour'", err.Error())
+	require.NotNil(manager.Config.Set("colour", "blue"))
+}
+
+// Test that invalid configuration values name the field
+func TestConfigValidation(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	require.Nil(manager.Config.Validate())
+
+	for key, value := range map[string]string{
+		"editor":         "no-such-editor-binary",
+		"backup_keep":    "-1",
+		"directory":      "relative/path",
+		"default_author": "",
+	} {
+		err := manager.Config.Set(key, value)
+		require.NotNil(err, key)
+		require.Contains(err.Error(), "invalid value for '"+key+"'")
+	}
+
+	// A file cannot be used as a directory
+	file := path.Join(t.TempDir(), "file")
+	require.Nil(os.WriteFile(file, []byte{}, 0600))
+	require.NotNil(manager.Config.Set("backup_directory", path.Join(file, "backups")))
+
+	// Directories that don't exist yet are valid if they can be created
+	require.Nil(manager.Config.Set("backup_directory", path.Join(t.TempDir(), "a", "b")))
+
+	// Invalid values set directly are caught when validating
+	manager.Config.Editor = "no-such-editor-binary"
+	require.NotNil(manager.Config.Validate())
+
+	field, err := note.LookupConfigField("editor")
+	require.Nil(err)
+	require.Equal(note.KindCommand, field.Kind)
+}
@@ -0,0 +1,644 @@
+package note
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query is a parsed query over notes, written in a small language modelled on
+// Obsidian's Dataview:
+//
+//	[TABLE field, ... | LIST] [FROM expr] [WHERE expr] [SORT field [ASC|DESC], ...] [LIMIT n]
+//
+// Every clause is optional, and WHERE can be left out before an expression.
+// Expressions combine comparisons of fields, such as 'status=draft',
+// 'priority>2', or 'created>=-7d', with tags such as '#work', links such as
+// '[[roadmap]]', and words or quoted phrases searched for in the title and
+// content of notes. They are joined with AND, which can be left out, OR, NOT,
+// and parentheses. Comparisons use the operators and fields of Where, plus
+// 'backlinks', the notes that link to a note. Dates can be compared with
+// 'today', 'yesterday', 'tomorrow', 'now', or a signed number of hours, days,
+// weeks, or years from now, such as '-7d'. Keywords are matched in any case
+type Query struct {
+	Fields []string  // Fields selected by a TABLE clause
+	Sort   []SortKey // Fields the results are sorted by, in order of priority
+	Limit  int       // Maximum number of results, or all results if zero
+
+	where queryNode // Condition notes must match, or nil to match every note
+}
+
+// SortKey is a field that query results are sorted by
+type SortKey struct {
+	Field      string // Name of the field
+	Descending bool   // Whether larger values come first
+}
+
+// QueryResult holds the notes that matched a query and the values of the
+// query's fields for each of them
+type QueryResult struct {
+	Columns []string // Filename and fields selected by the query, or the filename and title
+	Notes   []*Note  // Matching notes, sorted and limited by the query
+	Rows    [][]any  // Values of the columns for each note, nil where a note doesn't have a field
+}
+
+// Columns shown for queries without a TABLE clause
+var defaultColumns = []string{"filename", "title"}
+
+// Words with a meaning in queries, which must be quoted to be searched for
+var queryKeywords = []string{"table", "list", "from", "where", "sort", "asc", "desc", "limit", "and", "or", "not"}
+
+// Kinds of tokens in a query
+type tokenKind int
+
+const (
+	tokenEnd      tokenKind = iota // End of the query
+	tokenWord                      // A field name, value, or keyword
+	tokenString                    // A quoted phrase
+	tokenTag                       // A tag such as #work
+	tokenLink                      // A link such as [[roadmap]]
+	tokenOperator                  // A comparison operator
+	tokenOpen                      // An opening parenthesis
+	tokenClose                     // A closing parenthesis
+	tokenComma                     // A comma between fields
+)
+
+// A token of a query
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// Characters that end a word in a query
+const wordBreaks = `()=!<>~,"'`
+
+// Match the target of a link token, without its heading or label
+var linkTargetMatcher = regexp.MustCompile(`^([^\[\]|#]*)`)
+
+// Split a query into tokens
+func lexQuery(expr string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(' || r == ')' || r == ',':
+			kind := map[rune]tokenKind{'(': tokenOpen, ')': tokenClose, ',': tokenComma}[r]
+			tokens = append(tokens, token{kind: kind, text: string(r)})
+			i++
+
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, wrapf(ErrInvalidQuery, "invalid query '%s' (unterminated quote)", expr)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[i+1 : end])})
+			i = end + 1
+
+		case r == '[' && i+1 < len(runes) && runes[i+1] == '[':
+			rest := string(runes[i+2:])
+			end := strings.Index(rest, "]]")
+			if end < 0 {
+				return nil, wrapf(ErrInvalidQuery, "invalid query '%s' (unterminated link)", expr)
+			}
+			target := linkTargetMatcher.FindString(rest[:end])
+			tokens = append(tokens, token{kind: tokenLink, text: strings.ToLower(strings.TrimSpace(target))})
+			i += 2 + len([]rune(rest[:end])) + 2
+
+		case strings.ContainsRune("=!<>~", r):
+			op := ""
+			for _, candidate := range whereOperators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, wrapf(ErrInvalidQuery, "invalid query '%s' (unknown operator '%c')", expr, r)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op})
+			i += len(op)
+
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(wordBreaks, runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			i = end
+
+			if tag, ok := strings.CutPrefix(word, "#"); ok {
+				if tag == "" {
+					return nil, wrapf(ErrInvalidQuery, "invalid query '%s' (empty tag)", expr)
+				}
+				tokens = append(tokens, token{kind: tokenTag, text: strings.ToLower(tag)})
+			} else {
+				tokens = append(tokens, token{kind: tokenWord, text: word})
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd}), nil
+}
+
+// Parser of a query's tokens
+type queryParser struct {
+	expr   string
+	tokens []token
+	pos    int
+}
+
+// Return the current token
+func (p *queryParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// Return the current token and move to the next one
+func (p *queryParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEnd {
+		p.pos++
+	}
+	return t
+}
+
+// Return whether the current token is one of the keywords
+func (p *queryParser) keyword(keywords ...string) bool {
+	t := p.peek()
+	if t.kind != tokenWord {
+		return false
+	}
+
+	for _, keyword := range keywords {
+		if strings.EqualFold(t.text, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Return an error describing a problem at the current token
+func (p *queryParser) errorf(format string, v ...any) error {
+	return wrapf(ErrInvalidQuery, "invalid query '%s' (%s)", p.expr, fmt.Sprintf(format, v...))
+}
+
+// Return an error for an unexpected current token
+func (p *queryParser) unexpected() error {
+	if t := p.peek(); t.kind != tokenEnd {
+		return p.errorf("unexpected '%s'", t.text)
+	}
+	return p.errorf("unexpected end of query")
+}
+
+// Read a field name
+func (p *queryParser) field() (string, error) {
+	t := p.peek()
+	if t.kind != tokenWord || p.keyword(queryKeywords...) {
+		return "", p.unexpected()
+	}
+	if !fieldNameMatcher.MatchString(t.text) {
+		return "", p.errorf("invalid field name '%s'", t.text)
+	}
+
+	p.next()
+	return t.text, nil
+}
+
+// ParseQuery parses a query written in the query language
+func ParseQuery(expr string) (*Query, error) {
+	tokens, err := lexQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{expr: expr, tokens: tokens}
+	q := &Query{}
+
+	// Projection
+	if p.keyword("table") {
+		p.next()
+		for {
+			field, err := p.field()
+			if err != nil {
+				return nil, err
+			}
+			q.Fields = append(q.Fields, field)
+
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	} else if p.keyword("list") {
+		p.next()
+	}
+
+	// Conditions, where an expression without a clause is a WHERE clause
+	for _, clause := range []string{"from", "where"} {
+		if p.keyword(clause) {
+			p.next()
+		} else if clause == "from" || q.where != nil || !p.startsTerm() {
+			continue
+		}
+
+		node, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		q.where = andQuery(q.where, node)
+	}
+
+	// Sorting
+	if p.keyword("sort") {
+		p.next()
+		for {
+			field, err := p.field()
+			if err != nil {
+				return nil, err
+			}
+
+			key := SortKey{Field: field}
+			if p.keyword("asc", "desc") {
+				key.Descending = p.keyword("desc")
+				p.next()
+			}
+			q.Sort = append(q.Sort, key)
+
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	// Limit
+	if p.keyword("limit") {
+		p.next()
+		limit, err := strconv.Atoi(p.peek().text)
+		if p.peek().kind != tokenWord || err != nil || limit <= 0 {
+			return nil, p.errorf("limit must be a positive number")
+		}
+		p.next()
+		q.Limit = limit
+	}
+
+	if p.peek().kind != tokenEnd {
+		return nil, p.unexpected()
+	}
+
+	return q, nil
+}
+
+// Parse expressions joined by OR
+func (p *queryParser) or() (queryNode, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("or") {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+
+	return left, nil
+}
+
+// Parse expressions joined by AND, or by nothing
+func (p *queryParser) and() (queryNode, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if p.keyword("and") {
+			p.next()
+		} else if !p.startsTerm() {
+			return left, nil
+		}
+
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+// Return whether the current token starts a term of an expression
+func (p *queryParser) startsTerm() bool {
+	switch p.peek().kind {
+	case tokenString, tokenTag, tokenLink, tokenOpen:
+		return true
+	case tokenWord:
+		return p.keyword("not") || !p.keyword(queryKeywords...)
+	}
+
+	return false
+}
+
+// Parse a term that may be negated with NOT
+func (p *queryParser) not() (queryNode, error) {
+	if p.keyword("not") {
+		p.next()
+		node, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	}
+
+	return p.term()
+}
+
+// Parse a comparison, tag, link, text search, or parenthesized expression
+func (p *queryParser) term() (queryNode, error) {
+	t := p.peek()
+
+	switch t.kind {
+	case tokenOpen:
+		p.next()
+		node, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenClose {
+			return nil, p.unexpected()
+		}
+		p.next()
+		return node, nil
+
+	case tokenTag:
+		p.next()
+		return compareNode{comparison{field: "tags", op: "=", value: t.text}}, nil
+
+	case tokenLink:
+		p.next()
+		return compareNode{comparison{field: "links", op: "=", value: t.text}}, nil
+
+	case tokenString:
+		p.next()
+		return compareNode{comparison{field: "text", op: "~", value: t.text}}, nil
+
+	case tokenWord:
+		if p.keyword(queryKeywords...) {
+			return nil, p.unexpected()
+		}
+
+		// Words followed by an operator are compared, other words are searched for
+		if p.tokens[p.pos+1].kind != tokenOperator {
+			p.next()
+			return compareNode{comparison{field: "text", op: "~", value: t.text}}, nil
+		}
+
+		field, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		op := p.next().text
+
+		// Values can be left out to match notes without the field
+		value := ""
+		switch next := p.peek(); next.kind {
+		case tokenString, tokenLink:
+			value = p.next().text
+		case tokenWord:
+			if !p.keyword(queryKeywords...) {
+				value = p.next().text
+			}
+		}
+
+		return compareNode{comparison{field: field, op: op, value: value}}, nil
+	}
+
+	return nil, p.unexpected()
+}
+
+// A node of a query's condition
+type queryNode interface {
+	match(c *queryContext, note *Note) bool
+	needsContent() bool
+}
+
+// Both conditions must match
+type andNode struct{ left, right queryNode }
+
+func (n andNode) match(c *queryContext, note *Note) bool {
+	return n.left.match(c, note) && n.right.match(c, note)
+}
+
+func (n andNode) needsContent() bool { return n.left.needsContent() || n.right.needsContent() }
+
+// Either condition must match
+type orNode struct{ left, right queryNode }
+
+func (n orNode) match(c *queryContext, note *Note) bool {
+	return n.left.match(c, note) || n.right.match(c, note)
+}
+
+func (n orNode) needsContent() bool { return n.left.needsContent() || n.right.needsContent() }
+
+// The condition must not match
+type notNode struct{ node queryNode }
+
+func (n notNode) match(c *queryContext, note *Note) bool { return !n.node.match(c, note) }
+
+func (n notNode) needsContent() bool { return n.node.needsContent() }
+
+// A comparison of a field
+type compareNode struct{ comparison }
+
+func (n compareNode) match(c *queryContext, note *Note) bool {
+	value, ok := c.value(note, n.field)
+	return n.matchesField(value, ok, c.now)
+}
+
+func (n compareNode) needsContent() bool { return readsContent(n.field) }
+
+// Return both conditions joined with AND, or the other if either is nil
+func andQuery(left queryNode, right queryNode) queryNode {
+	if left == nil {
+		return right
+	}
+	return andNode{left, right}
+}
+
+// Return whether a field is read from the content of notes
+func readsContent(field string) bool {
+	field = strings.ToLower(field)
+	return contentFields[field] || field == "backlinks"
+}
+
+// State shared while a query runs
+type queryContext struct {
+	now       time.Time
+	notes     []*Note
+	backlinks map[string][]any // Notes that link to each note, built when first needed
+}
+
+// Return the value of a field of a note, including its backlinks
+func (c *queryContext) value(note *Note, field string) (any, bool) {
+	if !strings.EqualFold(field, "backlinks") {
+		return note.Value(field)
+	}
+
+	if c.backlinks == nil {
+		c.backlinks = map[string][]any{}
+		for _, source := range c.notes {
+			linked := map[string]bool{}
+			for _, link := range source.Links() {
+				if !linked[link.Target] {
+					linked[link.Target] = true
+					c.backlinks[link.Target] = append(c.backlinks[link.Target], source.Filename)
+				}
+			}
+		}
+	}
+
+	return append([]any{}, c.backlinks[note.Filename]...), true
+}
+
+// Return whether running the query needs the content of notes
+func (q *Query) needsContent() bool {
+	if q.where != nil && q.where.needsContent() {
+		return true
+	}
+	for _, field := range q.columns() {
+		if readsContent(field) {
+			return true
+		}
+	}
+	for _, key := range q.Sort {
+		if readsContent(key.Field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Return the fields shown for the query's results, which start with the
+// filename unless a TABLE clause selects it elsewhere
+func (q *Query) columns() []string {
+	if len(q.Fields) == 0 {
+		return defaultColumns
+	}
+
+	for _, field := range q.Fields {
+		if strings.EqualFold(field, "filename") {
+			return q.Fields
+		}
+	}
+	return append([]string{"filename"}, q.Fields...)
+}
+
+// Compare values of a field for sorting, returning -1, 0, or 1. Notes without
+// the field come last
+func compareSortValues(a any, aok bool, b any, bok bool) int {
+	switch {
+	case !aok && !bok:
+		return 0
+	case !aok:
+		return 1
+	case !bok:
+		return -1
+	}
+
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Compare(tb)
+		}
+	}
+
+	if order, ok := compareFieldValue(a, formatFieldValue(b), time.Local); ok {
+		return order
+	}
+	return strings.Compare(formatFieldValue(a), formatFieldValue(b))
+}
+
+// Query returns the notes that match a query, sorted, limited, and projected
+// into rows as the query describes. Notes without a SORT clause stay in
+// insertion order. The content of notes is loaded if the query reads it, such
+// as with tags, links, or text searches
+func (m *Manager) Query(q *Query) *QueryResult {
+	m.log().Debug("querying notes", "fields", q.Fields, "sort", q.Sort, "limit", q.Limit)
+
+	if q.needsContent() {
+		m.loadAll()
+	}
+
+	c := &queryContext{now: m.now(), notes: m.Notes}
+
+	notes := []*Note{}
+	for _, note := range m.Notes {
+		if q.where == nil || q.where.match(c, note) {
+			notes = append(notes, note)
+		}
+	}
+
+	// Sort the notes by each key in turn, keeping insertion order for equal values
+	sort.SliceStable(notes, func(i, j int) bool {
+		for _, key := range q.Sort {
+			a, aok := c.value(notes[i], key.Field)
+			b, bok := c.value(notes[j], key.Field)
+
+			order := compareSortValues(a, aok, b, bok)
+			if key.Descending && aok && bok {
+				order = -order
+			}
+			if order != 0 {
+				return order < 0
+			}
+		}
+
+		return false
+	})
+
+	if q.Limit > 0 && q.Limit < len(notes) {
+		notes = notes[:q.Limit]
+	}
+
+	// Project the notes into rows
+	result := &QueryResult{Columns: q.columns(), Notes: notes, Rows: [][]any{}}
+	for _, note := range notes {
+		row := []any{}
+		for _, column := range result.Columns {
+			value, ok := c.value(note, column)
+			if !ok {
+				value = nil
+			}
+			row = append(row, value)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result
+}
+
+// SavedQuery returns the saved search with the provided name, parsed
+func (c *Config) SavedQuery(name string) (*Query, error) {
+	expr, ok := c.Queries[name]
+	if !ok {
+		return nil, wrapf(ErrNotFound, "saved search '%s' not found", name)
+	}
+
+	return ParseQuery(expr)
+}
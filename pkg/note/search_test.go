@@ -0,0 +1,168 @@
+package note_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Return a manager backed by memory with notes to query
+func searchTestSetup(require *require.Assertions) *note.Manager {
+	manager, err := note.New(
+		note.WithConfigPath("/vault/config.json"),
+		note.WithManagerPath("/vault/manager.json"),
+		note.WithDirectory("/vault/entries"),
+		note.WithStore(&note.MemoryStore{}),
+	)
+	require.Nil(err)
+
+	notes := []struct {
+		filename string
+		content  string
+		fields   map[string]any
+	}{
+		{"roadmap", "# Roadmap\n\nPlans for #work and #q3\n", map[string]any{"status": "draft", "priority": 3}},
+		{"weekly", "# Weekly\n\nWeekly review of [[roadmap]] #work\n", map[string]any{"status": "done", "priority": 1, "due": "2024-06-01"}},
+		{"ideas", "# Ideas\n\nSee [[roadmap]] and [[weekly]]\n", map[string]any{"status": "draft", "priority": 5, "due": "2024-05-01"}},
+		{"misc", "nothing here\n", nil},
+	}
+	for _, n := range notes {
+		require.Nil(manager.CreateNote(n.filename))
+		require.Nil(manager.UpdateNote(n.filename, n.content))
+		if n.fields != nil {
+			require.Nil(manager.SetFields(n.filename, n.fields))
+		}
+	}
+
+	return manager
+}
+
+// Test running queries
+func TestQuery(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager := searchTestSetup(require)
+
+	query := func(expr string) []string {
+		q, err := note.ParseQuery(expr)
+		require.Nil(err, expr)
+
+		filenames := []string{}
+		for _, n := range manager.Query(q).Notes {
+			filenames = append(filenames, n.Filename)
+		}
+		return filenames
+	}
+
+	require.Equal([]string{"roadmap", "weekly", "ideas", "misc"}, query(""))
+	require.Equal([]string{"roadmap", "weekly"}, query("#work"))
+	require.Equal([]string{"roadmap"}, query("#work #q3"))
+	require.Equal([]string{"roadmap", "ideas"}, query("status=draft"))
+	require.Equal([]string{"roadmap"}, query("status=draft AND priority<5"))
+	require.Equal([]string{"weekly", "ideas"}, query("[[roadmap]]"))
+	require.Equal([]string{"roadmap"}, query("backlinks=weekly"))
+	require.Equal([]string{"weekly", "misc"}, query("'weekly review' or nothing"))
+	require.Equal([]string{"ideas", "misc"}, query("NOT #work"))
+	require.Equal([]string{"weekly", "ideas"}, query("(status=done OR priority>4) AND due<2025-01-01"))
+	require.Equal([]string{"roadmap", "weekly", "ideas", "misc"}, query("created>=-1d AND updated<=now"))
+	require.Equal([]string{}, query("created<yesterday"))
+	require.Equal([]string{"ideas", "roadmap", "weekly", "misc"}, query("SORT priority DESC"))
+	require.Equal([]string{"roadmap", "ideas"}, query("LIST FROM status=draft WHERE #work OR [[weekly]] SORT filename DESC"))
+	require.Equal([]string{"ideas", "weekly"}, query("WHERE due!='' SORT due LIMIT 2"))
+
+	// Tables project the selected fields after the filename
+	q, err := note.ParseQuery("TABLE status, priority, backlinks FROM #work SORT priority")
+	require.Nil(err)
+	result := manager.Query(q)
+	require.Equal([]string{"filename", "status", "priority", "backlinks"}, result.Columns)
+	require.Equal([][]any{
+		{"weekly", "done", 1.0, []any{"ideas"}},
+		{"roadmap", "draft", 3.0, []any{"weekly", "ideas"}},
+	}, result.Rows)
+
+	q, err = note.ParseQuery("TABLE due")
+	require.Nil(err)
+	require.Nil(manager.Query(q).Rows[0][1])
+}
+
+// Test that dates match relative dates by calendar day outside of UTC
+func TestQueryRelativeDates(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager := searchTestSetup(require)
+
+	local := time.Local
+	defer func() { time.Local = local }()
+	time.Local = time.FixedZone("UTC-5", -5*60*60)
+
+	today := time.Now().Format("2006-01-02")
+	require.Nil(manager.SetFields("misc", map[string]any{"due": today}))
+
+	query := func(expr string) []string {
+		q, err := note.ParseQuery(expr)
+		require.Nil(err, expr)
+
+		filenames := []string{}
+		for _, n := range manager.Query(q).Notes {
+			filenames = append(filenames, n.Filename)
+		}
+		return filenames
+	}
+
+	require.Equal([]string{"misc"}, query("due=today"))
+	require.Equal([]string{"misc"}, query("due>yesterday AND due<tomorrow"))
+	require.Equal([]string{"weekly", "ideas"}, query("due<today"))
+	require.Equal([]string{}, query("due>today"))
+}
+
+// Test that invalid queries are rejected
+func TestParseQueryErrors(t *testing.T) {
+	require := require.New(t)
+
+	for _, expr := range []string{
+		"status=draft AND",
+		"(status=draft",
+		"'open",
+		"[[open",
+		"status ! draft",
+		"TABLE",
+		"SORT",
+		"LIMIT 0",
+		"#",
+		"9x=1",
+		"status=draft extra)",
+	} {
+		_, err := note.ParseQuery(expr)
+		require.True(errors.Is(err, note.ErrInvalidQuery), expr)
+	}
+}
+
+// Test saving searches in the config
+func TestSavedQuery(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager := searchTestSetup(require)
+
+	require.Nil(manager.Config.Set("queries.drafts", "status=draft SORT priority DESC"))
+	require.True(errors.Is(manager.Config.Set("queries.bad", "SORT"), note.ErrInvalidConfig))
+	require.True(errors.Is(manager.Config.Set("queries.Bad Name", "#work"), note.ErrInvalidConfig))
+
+	q, err := manager.Config.SavedQuery("drafts")
+	require.Nil(err)
+	result := manager.Query(q)
+	require.Equal("ideas", result.Notes[0].Filename)
+	require.Len(result.Notes, 2)
+
+	_, err = manager.Config.SavedQuery("missing")
+	require.True(errors.Is(err, note.ErrNotFound))
+
+	// Saved searches are listed with the config and can be removed
+	value, err := manager.Config.Get("queries.drafts")
+	require.Nil(err)
+	require.Equal("status=draft SORT priority DESC", value)
+	require.Nil(manager.Config.Set("queries.drafts", ""))
+	require.Empty(manager.Config.Queries)
+}
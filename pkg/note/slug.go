@@ -0,0 +1,127 @@
+package note
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Filename used for titles that have no letters or numbers
+const untitledFilename = "untitled"
+
+// Letters that are written with Latin letters in filenames. Latin letters with
+// accents are handled by removing the accents
+var transliterations = map[rune]string{
+	// Latin letters that don't decompose into a base letter and an accent
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'þ': "th", 'ł': "l", 'ı': "i", 'ħ': "h",
+
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g", 'д': "d", 'е': "e", 'ё': "e", 'є': "ye", 'ж': "zh",
+	'з': "z", 'и': "i", 'і': "i", 'ї': "yi", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh",
+	'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i", 'κ': "k",
+	'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t",
+	'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Slugify returns a filename for a title. Letters are lowercased, accents are
+// removed, Cyrillic and Greek letters are written with Latin letters, and runs
+// of other characters become a single '-'. Letters of other scripts, such as
+// Chinese, Arabic, or Hindi, are kept as they are along with their combining
+// marks. Titles without any letters or numbers become 'untitled'
+func Slugify(title string) string {
+	slug := strings.Builder{}
+	separate := false
+	kept := false // Whether the last letter was kept as it is
+
+	write := func(s string) {
+		if separate && slug.Len() > 0 {
+			slug.WriteByte('-')
+		}
+		separate = false
+		slug.WriteString(s)
+	}
+
+	for _, r := range norm.NFC.String(strings.ToLower(title)) {
+		// Combining marks, such as the vowel signs of Indic scripts, belong to
+		// the letter before them, and are dropped with the accents of Latin letters
+		if unicode.Is(unicode.M, r) {
+			if kept {
+				slug.WriteRune(r)
+			}
+			continue
+		}
+		kept = false
+
+		if r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			write(string(r))
+			continue
+		}
+
+		// Transliterate letters, checking accented letters without their accents
+		base := []rune(norm.NFD.String(string(r)))[0]
+		if latin, ok := transliterations[base]; ok {
+			write(latin)
+		} else if base < unicode.MaxASCII && unicode.IsLetter(base) {
+			write(string(base))
+		} else if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			write(string(r))
+			kept = true
+		} else {
+			separate = true
+		}
+	}
+
+	if slug.Len() == 0 {
+		return untitledFilename
+	}
+
+	return slug.String()
+}
+
+// Return a filename for a title that no note in the manager uses, adding a
+// number to the slug of the title if it is taken, such as 'standup-2'
+func (m *Manager) uniqueFilename(title string) string {
+	slug := Slugify(title)
+
+	filename := slug
+	for i := 2; ; i++ {
+		if ok, _ := m.contains(filename); !ok {
+			return filename
+		}
+		filename = slug + "-" + strconv.Itoa(i)
+	}
+}
+
+// Return the language used to capitalize titles made from filenames, which
+// defaults to English
+func (c *Config) titleTag() language.Tag {
+	if c != nil && c.TitleLanguage != "" {
+		if tag, err := language.Parse(c.TitleLanguage); err == nil {
+			return tag
+		}
+	}
+
+	return language.English
+}
+
+// Return a title made from a filename, with each word capitalized
+func (c *Config) titleFromFilename(filename string) string {
+	caser := cases.Title(c.titleTag())
+
+	words := []string{}
+	for _, word := range strings.Split(filename, "-") {
+		if word != "" {
+			words = append(words, caser.String(word))
+		}
+	}
+
+	return strings.Join(words, " ")
+}
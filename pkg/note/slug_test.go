@@ -0,0 +1,96 @@
+package note_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test turning titles in different scripts into filenames
+func TestSlugify(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal("daily-standup", note.Slugify("Daily Standup"))
+	require.Equal("cafe-creme-brulee", note.Slugify("  Café -- Crème Brûlée! "))
+	require.Equal("strasse-und-smorrebrod", note.Slugify("Straße und Smørrebrød"))
+	require.Equal("zametki-o-vstreche", note.Slugify("Заметки о встрече"))
+	require.Equal("simeioseis", note.Slugify("Σημειώσεις"))
+	require.Equal("会议记录-2024", note.Slugify("会议记录 2024"))
+	require.Equal("नमस्कार-दुनिया", note.Slugify("नमस्कार दुनिया"))
+	require.Equal("வணக்கம்", note.Slugify("வணக்கம்"))
+	require.Equal("q3_plan", note.Slugify("Q3_plan"))
+	require.Equal("untitled", note.Slugify("!?"))
+}
+
+// Test creating notes from titles and changing their titles
+func TestCreateNoteWithTitle(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+
+	filename, err := manager.CreateNoteWithTitle("Café Notes")
+	require.Nil(err)
+	require.Equal("cafe-notes", filename)
+
+	n := manager.GetNote(filename)
+	require.Equal("Café Notes", n.Title)
+	require.Equal("# Café Notes\n\n", n.Content)
+
+	// Titles that make the same filename are numbered
+	filename, err = manager.CreateNoteWithTitle("Cafe notes")
+	require.Nil(err)
+	require.Equal("cafe-notes-2", filename)
+
+	filename, err = manager.CreateNoteWithTitle("会议记录")
+	require.Nil(err)
+	require.Equal("会议记录", filename)
+
+	filename, err = manager.CreateNoteWithTitle("नमस्कार दुनिया")
+	require.Nil(err)
+	require.Equal("नमस्कार-दुनिया", filename)
+	require.Equal("नमस्कार दुनिया", manager.GetNote(filename).Title)
+
+	_, err = manager.CreateNoteWithTitle(" ")
+	require.True(errors.Is(err, note.ErrInvalidName))
+
+	// Titles resolve to their note
+	resolved, err := manager.ResolveNote("café notes")
+	require.Nil(err)
+	require.Equal("cafe-notes", resolved)
+
+	// Titles can be changed without changing the filename
+	require.Nil(manager.SetTitle("cafe-notes-2", "Coffee ☕"))
+	require.Equal("Coffee ☕", manager.GetNote("cafe-notes-2").Title)
+	require.True(errors.Is(manager.SetTitle("missing", "Title"), note.ErrNotFound))
+
+	// Titles are saved with the manager
+	require.Nil(manager.Load())
+	require.Equal("Coffee ☕", manager.GetNote("cafe-notes-2").Title)
+}
+
+// Test that titles made from filenames are capitalized in the configured language
+func TestTitleLanguage(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	config := noteTestSetup()
+
+	n, err := note.NewNote(config, "ijsje-in-istanbul")
+	require.Nil(err)
+	require.Equal("Ijsje In Istanbul", n.Title)
+
+	require.Nil(config.Set("title_language", "nl"))
+	n, err = note.NewNote(config, "ijsje-in-istanbul")
+	require.Nil(err)
+	require.Equal("IJsje In Istanbul", n.Title)
+
+	require.Nil(config.Set("title_language", "tr"))
+	n, err = note.NewNote(config, "ijsje-in-istanbul")
+	require.Nil(err)
+	require.Equal("İjsje İn İstanbul", n.Title)
+
+	err = config.Set("title_language", "not a language")
+	require.True(errors.Is(err, note.ErrInvalidConfig))
+}
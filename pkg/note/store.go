@@ -0,0 +1,226 @@
+package note
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store reads and writes the files a manager owns: its config file, its
+// manager file, and the files of its notes. Archives, sync remotes, and
+// temporary files are always read from and written to disk
+type Store interface {
+	ReadFile(name string) ([]byte, error)                       // Read the content of a file
+	WriteFile(name string, data []byte, perm fs.FileMode) error // Create or replace a file
+	Remove(name string) error                                   // Remove a file
+	MkdirAll(name string, perm fs.FileMode) error               // Create a directory and its parents
+	Stat(name string) (fs.FileInfo, error)                      // Describe a file or directory
+	ReadDir(name string) ([]string, error)                      // List the names in a directory, sorted
+}
+
+// OSStore is a store backed by the filesystem
+type OSStore struct{}
+
+func (OSStore) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (OSStore) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+func (OSStore) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (OSStore) MkdirAll(name string, perm fs.FileMode) error {
+	return os.MkdirAll(name, perm)
+}
+
+func (OSStore) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (OSStore) ReadDir(name string) ([]string, error) {
+	entries, err := os.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	return names, nil
+}
+
+// MemoryStore is a store that keeps files in memory, which is useful for tests
+// and for embedding managers that shouldn't touch the filesystem. The zero
+// value is an empty store that is safe for concurrent use
+type MemoryStore struct {
+	mu          sync.RWMutex
+	files       map[string][]byte
+	modTimes    map[string]time.Time
+	directories map[string]bool
+}
+
+// memoryFileInfo describes a file or directory in a memory store
+type memoryFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i memoryFileInfo) Name() string       { return i.name }
+func (i memoryFileInfo) Size() int64        { return i.size }
+func (i memoryFileInfo) ModTime() time.Time { return i.modTime }
+func (i memoryFileInfo) IsDir() bool        { return i.dir }
+func (i memoryFileInfo) Sys() any           { return nil }
+
+func (i memoryFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0600
+}
+
+func (s *MemoryStore) ReadFile(name string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.files[path.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return append([]byte{}, data...), nil
+}
+
+func (s *MemoryStore) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = path.Clean(name)
+	if s.directories[name] {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if parent := path.Dir(name); parent != "/" && parent != "." && !s.directories[parent] {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if s.files == nil {
+		s.files = map[string][]byte{}
+		s.modTimes = map[string]time.Time{}
+	}
+	s.files[name] = append([]byte{}, data...)
+	s.modTimes[name] = time.Now()
+
+	return nil
+}
+
+func (s *MemoryStore) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = path.Clean(name)
+	if _, ok := s.files[name]; ok {
+		delete(s.files, name)
+		delete(s.modTimes, name)
+		return nil
+	}
+
+	if s.directories[name] {
+		for file := range s.files {
+			if strings.HasPrefix(file, name+"/") {
+				return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+			}
+		}
+		delete(s.directories, name)
+		return nil
+	}
+
+	return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+}
+
+func (s *MemoryStore) MkdirAll(name string, perm fs.FileMode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.directories == nil {
+		s.directories = map[string]bool{}
+	}
+
+	for name = path.Clean(name); name != "/" && name != "."; name = path.Dir(name) {
+		if _, ok := s.files[name]; ok {
+			return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+		}
+		s.directories[name] = true
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Stat(name string) (fs.FileInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	name = path.Clean(name)
+	if data, ok := s.files[name]; ok {
+		return memoryFileInfo{name: path.Base(name), size: int64(len(data)), modTime: s.modTimes[name]}, nil
+	}
+	if s.directories[name] || name == "/" || name == "." {
+		return memoryFileInfo{name: path.Base(name), dir: true}, nil
+	}
+
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+func (s *MemoryStore) ReadDir(name string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	name = path.Clean(name)
+	if !s.directories[name] && name != "/" && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	children := map[string]bool{}
+	for file := range s.files {
+		if path.Dir(file) == name {
+			children[path.Base(file)] = true
+		}
+	}
+	for directory := range s.directories {
+		if path.Dir(directory) == name {
+			children[path.Base(directory)] = true
+		}
+	}
+
+	names := []string{}
+	for child := range children {
+		names = append(names, child)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Return the names of every file in the store, sorted
+func (s *MemoryStore) Files() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := []string{}
+	for name := range s.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,543 @@
+package note
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SyncResult describes the changes made while syncing with a remote
+type SyncResult struct {
+	Pushed    []string // Notes copied from the manager to the remote
+	Pulled    []string // Notes copied from the remote to the manager
+	Merged    []string // Notes whose concurrent edits were merged
+	Conflicts []string // Conflict copies created for edits that could not be merged
+	Deleted   []string // Notes deleted on one side because they were deleted on the other
+}
+
+// syncState records the version of every note after the last sync with a
+// remote. It is the common base used to detect and merge concurrent edits
+type syncState struct {
+	Remote string            `json:"remote"` // Remote the state belongs to
+	Notes  map[string]string `json:"notes"`  // Checksums of each note's content after the last sync
+}
+
+// syncSide holds one side of a sync, either the manager or the remote
+type syncSide struct {
+	notes   map[string]*Note // Notes on this side, by filename
+	changed bool             // Whether this side was modified and must be saved
+}
+
+// Return the directory where the sync state of the provided remote is stored
+func (m *Manager) syncStateDirectory(remote string) string {
+	return path.Join(path.Dir(m.managerFile()), "sync", checksum([]byte(remote))[:16])
+}
+
+// Load the sync state of a remote, returning an empty state if the remote has
+// never been synced
+func (m *Manager) loadSyncState(remote string) (*syncState, error) {
+	state := &syncState{Remote: remote, Notes: map[string]string{}}
+
+	file, err := os.ReadFile(path.Join(m.syncStateDirectory(remote), "state.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(file, state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Read the base content of a note from the sync state of a remote
+func (m *Manager) loadSyncBase(remote string, filename string) (string, error) {
+	content, err := os.ReadFile(path.Join(m.syncStateDirectory(remote), "base", filename+".md"))
+	return string(content), err
+}
+
+// Save the sync state of a remote along with the base content of every note
+func (m *Manager) saveSyncState(remote string, notes map[string]*Note) error {
+	directory := m.syncStateDirectory(remote)
+	state := &syncState{Remote: remote, Notes: map[string]string{}}
+
+	// Replace the base content of every note
+	if err := os.RemoveAll(path.Join(directory, "base")); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path.Join(directory, "base"), 0700); err != nil {
+		return err
+	}
+
+	for filename, note := range notes {
+		state.Notes[filename] = checksum([]byte(note.Content))
+
+		if err := os.WriteFile(path.Join(directory, "base", filename+".md"), []byte(note.Content), 0600); err != nil {
+			return err
+		}
+	}
+
+	file, err := json.MarshalIndent(state, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path.Join(directory, "state.json"), file, 0600)
+}
+
+// Return whether the provided directory is a bare git repository
+func isBareGitRepository(directory string) bool {
+	output, err := exec.Command("git", "-C", directory, "rev-parse", "--is-bare-repository").Output()
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
+
+// Run a git command in the provided directory, returning its combined output
+// as part of the error if it fails
+func runGit(directory string, args ...string) error {
+	output, err := exec.Command("git", append([]string{"-C", directory}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %s failed (err: %v): %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// Sync exchanges notes and their metadata in both directions with a remote.
+// The remote can be a directory, such as a mounted share, or the path of a
+// bare git repository. Notes that were edited on both sides since the last
+// sync are merged line by line. If the edits cannot be merged, the most
+// recently updated version is kept and the other version is saved as a
+// conflict copy on both sides
+func (m *Manager) Sync(remote string) (*SyncResult, error) {
+	m.log().Info("syncing notes", "remote", remote)
+
+	// Identify the remote by its absolute path so the sync state is stable
+	remote, err := filepath.Abs(remote)
+	if err != nil {
+		m.log().Error("failed to resolve remote path", "err", err)
+		return nil, err
+	}
+
+	if !isBareGitRepository(remote) {
+		return m.syncDirectory(remote, remote)
+	}
+
+	m.log().Debug("remote is a git repository, cloning it")
+
+	// Sync with a clone of the repository and push the changes back
+	clone, err := os.MkdirTemp("", "note-sync-*")
+	if err != nil {
+		m.log().Error("failed to create clone directory", "err", err)
+		return nil, err
+	}
+	defer os.RemoveAll(clone)
+
+	if err := runGit(clone, "clone", "--quiet", remote, "."); err != nil {
+		m.log().Error("failed to clone remote", "err", err)
+		return nil, err
+	}
+
+	result, err := m.syncDirectory(clone, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runGit(clone, "add", "--all"); err != nil {
+		m.log().Error("failed to stage changes", "err", err)
+		return nil, err
+	}
+
+	// Only commit if the remote changed
+	if err := runGit(clone, "diff", "--cached", "--quiet"); err != nil {
+		hostname, _ := os.Hostname()
+		message := fmt.Sprintf("note sync from %s", hostname)
+
+		if err := runGit(clone, "-c", "user.name=note", "-c", "user.email=note@localhost", "commit", "--quiet", "-m", message); err != nil {
+			m.log().Error("failed to commit changes", "err", err)
+			return nil, err
+		}
+		if err := runGit(clone, "push", "--quiet", "origin", "HEAD"); err != nil {
+			m.log().Error("failed to push changes", "err", err)
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Sync the manager with a remote directory that stores notes in the same layout
+// as the manager: a 'manager.json' file and an 'entries' directory. The
+// provided key identifies the remote's sync state
+func (m *Manager) syncDirectory(directory string, key string) (*SyncResult, error) {
+	result := &SyncResult{}
+
+	if err := m.LoadContent(m.Notes); err != nil {
+		return nil, err
+	}
+
+	// Load the notes on both sides and the common base. Encrypted notes are
+	// synced as their encrypted files
+	local := &syncSide{notes: map[string]*Note{}}
+	for _, note := range m.Notes {
+		if !note.Encrypted {
+			local.notes[note.Filename] = note
+			continue
+		}
+
+		content, err := m.files().ReadFile(m.NotePath(note.Filename))
+		if err != nil {
+			m.log().Error("failed to read note from file", "filename", note.Filename, "err", err)
+			return nil, err
+		}
+
+		encrypted := copyNote(note)
+		encrypted.Content = string(content)
+		local.notes[note.Filename] = encrypted
+	}
+
+	remote, err := loadRemoteNotes(directory)
+	if err != nil {
+		m.log().Error("failed to read remote notes", "err", err)
+		return nil, err
+	}
+
+	state, err := m.loadSyncState(key)
+	if err != nil {
+		m.log().Error("failed to read sync state", "err", err)
+		return nil, err
+	}
+
+	// Visit every note known to either side or the base in a stable order
+	filenames := []string{}
+	seen := map[string]bool{}
+	for _, notes := range []map[string]*Note{local.notes, remote.notes} {
+		for filename := range notes {
+			if !seen[filename] {
+				seen[filename] = true
+				filenames = append(filenames, filename)
+			}
+		}
+	}
+	for filename := range state.Notes {
+		if !seen[filename] {
+			seen[filename] = true
+			filenames = append(filenames, filename)
+		}
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		localNote, inLocal := local.notes[filename]
+		remoteNote, inRemote := remote.notes[filename]
+		baseSum, inBase := state.Notes[filename]
+
+		localSum, remoteSum := "", ""
+		if inLocal {
+			localSum = checksum([]byte(localNote.Content))
+		}
+		if inRemote {
+			remoteSum = checksum([]byte(remoteNote.Content))
+		}
+
+		switch {
+		case !inLocal && !inRemote:
+			// Deleted on both sides
+
+		case inLocal && !inRemote:
+			if inBase && localSum == baseSum {
+				// Deleted on the remote and unchanged locally
+				delete(local.notes, filename)
+				local.changed = true
+				result.Deleted = append(result.Deleted, filename)
+			} else {
+				// New or changed locally, which wins over a remote deletion
+				remote.notes[filename] = copyNote(localNote)
+				remote.changed = true
+				result.Pushed = append(result.Pushed, filename)
+			}
+
+		case !inLocal && inRemote:
+			if inBase && remoteSum == baseSum {
+				// Deleted locally and unchanged on the remote
+				delete(remote.notes, filename)
+				remote.changed = true
+				result.Deleted = append(result.Deleted, filename)
+			} else {
+				// New or changed on the remote, which wins over a local deletion
+				local.notes[filename] = copyNote(remoteNote)
+				local.changed = true
+				result.Pulled = append(result.Pulled, filename)
+			}
+
+		case localSum == remoteSum:
+			// Same content on both sides, so only the metadata is reconciled
+			if !localNote.UpdatedAt.Equal(remoteNote.UpdatedAt) {
+				if localNote.UpdatedAt.After(remoteNote.UpdatedAt) {
+					remote.notes[filename] = copyNote(localNote)
+					remote.changed = true
+				} else {
+					local.notes[filename] = copyNote(remoteNote)
+					local.changed = true
+				}
+			}
+
+		case inBase && localSum == baseSum:
+			// Only changed on the remote
+			local.notes[filename] = copyNote(remoteNote)
+			local.changed = true
+			result.Pulled = append(result.Pulled, filename)
+
+		case inBase && remoteSum == baseSum:
+			// Only changed locally
+			remote.notes[filename] = copyNote(localNote)
+			remote.changed = true
+			result.Pushed = append(result.Pushed, filename)
+
+		default:
+			// Changed on both sides, so attempt a three-way merge with the common
+			// base. Encrypted notes cannot be merged
+			if inBase && !localNote.Encrypted && !remoteNote.Encrypted {
+				base, err := m.loadSyncBase(key, filename)
+				if err != nil {
+					m.log().Error("failed to read base of note", "filename", filename, "err", err)
+					return nil, err
+				}
+
+				if content, ok := Merge3(base, localNote.Content, remoteNote.Content); ok {
+					m.log().Info("merged concurrent edits of note", "filename", filename)
+
+					merged := copyNote(localNote)
+					merged.Content = content
+					merged.UpdatedAt = m.now()
+
+					local.notes[filename] = merged
+					remote.notes[filename] = copyNote(merged)
+					local.changed, remote.changed = true, true
+					result.Merged = append(result.Merged, filename)
+					break
+				}
+			}
+
+			m.log().Info("conflicting edits of note, keeping both versions", "filename", filename)
+
+			// Keep the most recent version and save the other as a conflict copy
+			kept, other := localNote, remoteNote
+			if remoteNote.UpdatedAt.After(localNote.UpdatedAt) {
+				kept, other = remoteNote, localNote
+			}
+
+			conflict := copyNote(other)
+			conflict.Filename = conflictFilename(filename, other.UpdatedAt, local.notes, remote.notes)
+
+			local.notes[filename] = copyNote(kept)
+			remote.notes[filename] = copyNote(kept)
+			local.notes[conflict.Filename] = conflict
+			remote.notes[conflict.Filename] = copyNote(conflict)
+			local.changed, remote.changed = true, true
+			result.Conflicts = append(result.Conflicts, conflict.Filename)
+		}
+	}
+
+	// Save the remote
+	if remote.changed {
+		m.log().Debug("saving remote notes")
+
+		if err := saveRemoteNotes(directory, remote.notes); err != nil {
+			m.log().Error("failed to save remote notes", "err", err)
+			return nil, err
+		}
+	}
+
+	// Save the manager
+	if local.changed {
+		m.log().Debug("saving local notes")
+
+		before := m.snapshot()
+		notes := []*Note{}
+		for _, note := range m.Notes {
+			updated, ok := local.notes[note.Filename]
+			if ok && updated.Encrypted == note.Encrypted {
+				notes = append(notes, updated)
+				continue
+			}
+
+			// Remove the files of deleted notes and notes stored in a different format
+			if err := m.files().Remove(m.NotePath(note.Filename)); err != nil && !os.IsNotExist(err) {
+				m.log().Error("failed to remove note file", "err", err)
+				return nil, err
+			}
+			if ok {
+				notes = append(notes, updated)
+			}
+		}
+		for _, filename := range sortedFilenames(local.notes) {
+			if ok, _ := m.contains(filename); !ok {
+				notes = append(notes, local.notes[filename])
+			}
+		}
+		m.Notes = notes
+
+		// Write encrypted files as is, keeping their content out of the manager
+		for i, note := range m.Notes {
+			if !note.Encrypted {
+				continue
+			}
+
+			if err := m.files().WriteFile(m.NotePath(note.Filename), []byte(note.Content), 0600); err != nil {
+				m.log().Error("failed to save note to file", "filename", note.Filename, "err", err)
+				return nil, err
+			}
+
+			m.Notes[i] = copyNote(note)
+			m.Notes[i].Content = ""
+		}
+
+		if err := m.Save(); err != nil {
+			m.log().Error("failed to save manager", "err", err)
+			return nil, err
+		}
+
+		m.emitSince(before)
+	}
+
+	// Record the synced version of every note as the base of the next sync
+	if err := m.saveSyncState(key, local.notes); err != nil {
+		m.log().Error("failed to save sync state", "err", err)
+		return nil, err
+	}
+
+	m.log().Debug("successfully synced notes")
+	return result, nil
+}
+
+// Return a copy of a note
+func copyNote(note *Note) *Note {
+	copied := *note
+	return &copied
+}
+
+// Return the filenames of the provided notes in sorted order
+func sortedFilenames(notes map[string]*Note) []string {
+	filenames := []string{}
+	for filename := range notes {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	return filenames
+}
+
+// Return an unused filename for a conflict copy of a note
+func conflictFilename(filename string, t time.Time, sides ...map[string]*Note) string {
+	base := filename + "-conflict-" + t.Format("20060102-150405")
+
+	candidate := base
+	for i := 2; ; i++ {
+		used := false
+		for _, notes := range sides {
+			if _, ok := notes[candidate]; ok {
+				used = true
+			}
+		}
+		if !used {
+			return candidate
+		}
+
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// Return the name of the file a note is stored in on a remote
+func remoteFilename(note *Note) string {
+	if note.Encrypted {
+		return note.Filename + ".md.enc"
+	}
+
+	return note.Filename + ".md"
+}
+
+// Read the notes stored in a remote directory
+func loadRemoteNotes(directory string) (*syncSide, error) {
+	side := &syncSide{notes: map[string]*Note{}}
+
+	file, err := os.ReadFile(path.Join(directory, "manager.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		// An empty remote is created on the first sync
+		return side, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manager := &Manager{}
+	if err := json.Unmarshal(file, manager); err != nil {
+		return nil, err
+	}
+
+	for _, note := range manager.Notes {
+		if !filenameMatcher.MatchString(note.Filename) {
+			return nil, wrapf(ErrInvalidName, "invalid name '%s'", note.Filename)
+		}
+
+		content, err := os.ReadFile(path.Join(directory, "entries", remoteFilename(note)))
+		if err != nil {
+			return nil, err
+		}
+		note.Content = string(content)
+
+		side.notes[note.Filename] = note
+	}
+
+	return side, nil
+}
+
+// Write the provided notes to a remote directory, removing notes that no
+// longer exist
+func saveRemoteNotes(directory string, notes map[string]*Note) error {
+	entries := path.Join(directory, "entries")
+	if err := os.MkdirAll(entries, 0755); err != nil {
+		return err
+	}
+
+	// Remove the files of deleted notes
+	files, err := os.ReadDir(entries)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		filename := strings.TrimSuffix(strings.TrimSuffix(file.Name(), ".enc"), ".md")
+		if note, exists := notes[filename]; !exists || remoteFilename(note) != file.Name() {
+			if err := os.Remove(path.Join(entries, file.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Write every note and the metadata of every note
+	manager := &Manager{Notes: []*Note{}}
+	for _, filename := range sortedFilenames(notes) {
+		note := notes[filename]
+		manager.Notes = append(manager.Notes, note)
+
+		if err := os.WriteFile(path.Join(entries, remoteFilename(note)), []byte(note.Content), 0600); err != nil {
+			return err
+		}
+	}
+
+	file, err := json.MarshalIndent(manager, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path.Join(directory, "manager.json"), file, 0600)
+}
@@ -0,0 +1,204 @@
+package note_test
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Setup before each test by creating a second manager that shares the
+// remote, as if it were on another machine
+func syncTestPeer(t *testing.T, remote string) (*note.Manager, func()) {
+	// Get working directory
+	wd, err := os.Getwd()
+	require.Nil(t, err)
+
+	// Switch to the peer's paths
+	peer := path.Join(wd, "testing/dirty/peer")
+	note.ModifyDefaultDirectoryPath(path.Join(peer, "entries"))
+	note.ModifyConfigPath(path.Join(peer, "config.json"))
+	note.ModifyManagerPath(path.Join(peer, "manager.json"))
+
+	manager, err := note.GetManager()
+	require.Nil(t, err)
+
+	// Return a function that switches back to the paths of the first manager
+	restore := func() {
+		note.ModifyDefaultDirectoryPath(path.Join(wd, "testing/dirty/entries/"))
+		note.ModifyConfigPath(path.Join(wd, "testing/dirty/config.json"))
+		note.ModifyManagerPath(path.Join(wd, "testing/dirty/manager.json"))
+	}
+
+	return manager, restore
+}
+
+// Test syncing notes between two managers through a directory
+func TestSync(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	remote := "testing/dirty/remote"
+
+	// Push a note to the remote
+	require.Nil(manager.CreateNote("note-1"))
+	result, err := manager.Sync(remote)
+	require.Nil(err)
+	require.Equal([]string{"note-1"}, result.Pushed)
+
+	// Pull the note on the peer and edit it
+	peer, restore := syncTestPeer(t, remote)
+	result, err = peer.Sync(remote)
+	require.Nil(err)
+	require.Equal([]string{"note-1"}, result.Pulled)
+	require.Equal("# Note 1\n\n", peer.GetNote("note-1").Content)
+
+	require.Nil(peer.UpdateNote("note-1", "# Note 1\n\npeer edit\n"))
+	require.Nil(peer.CreateNote("note-2"))
+	_, err = peer.Sync(remote)
+	require.Nil(err)
+	restore()
+
+	// Pull the peer's changes
+	result, err = manager.Sync(remote)
+	require.Nil(err)
+	require.Equal([]string{"note-1", "note-2"}, result.Pulled)
+	require.Equal("# Note 1\n\npeer edit\n", manager.GetNote("note-1").Content)
+
+	content, err := os.ReadFile("./testing/dirty/entries/note-2.md")
+	require.Nil(err)
+	require.Equal("# Note 2\n\n", string(content))
+
+	// Delete a note and propagate the deletion
+	require.Nil(manager.DeleteNote("note-2"))
+	result, err = manager.Sync(remote)
+	require.Nil(err)
+	require.Equal([]string{"note-2"}, result.Deleted)
+
+	peer, restore = syncTestPeer(t, remote)
+	defer restore()
+	result, err = peer.Sync(remote)
+	require.Nil(err)
+	require.Equal([]string{"note-2"}, result.Deleted)
+	require.Nil(peer.GetNote("note-2"))
+}
+
+// Test merging and keeping conflicting concurrent edits
+func TestSyncConcurrentEdits(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	remote := "testing/dirty/remote"
+
+	require.Nil(manager.CreateNote("note-1"))
+	require.Nil(manager.UpdateNote("note-1", "# Note 1\n\none\ntwo\nthree\n"))
+	_, err = manager.Sync(remote)
+	require.Nil(err)
+
+	// Edit different lines on both sides
+	peer, restore := syncTestPeer(t, remote)
+	_, err = peer.Sync(remote)
+	require.Nil(err)
+	require.Nil(peer.UpdateNote("note-1", "# Note 1\n\none\ntwo\nTHREE\n"))
+	_, err = peer.Sync(remote)
+	require.Nil(err)
+	restore()
+
+	require.Nil(manager.UpdateNote("note-1", "# Note 1\n\nONE\ntwo\nthree\n"))
+	result, err := manager.Sync(remote)
+	require.Nil(err)
+	require.Equal([]string{"note-1"}, result.Merged)
+	require.Equal("# Note 1\n\nONE\ntwo\nTHREE\n", manager.GetNote("note-1").Content)
+
+	// Edit the same line on both sides
+	peer, restore = syncTestPeer(t, remote)
+	_, err = peer.Sync(remote)
+	require.Nil(err)
+	require.Nil(peer.UpdateNote("note-1", "# Note 1\n\npeer\ntwo\nTHREE\n"))
+	_, err = peer.Sync(remote)
+	require.Nil(err)
+	restore()
+
+	time.Sleep(10 * time.Millisecond)
+	require.Nil(manager.UpdateNote("note-1", "# Note 1\n\nlocal\ntwo\nTHREE\n"))
+	result, err = manager.Sync(remote)
+	require.Nil(err)
+	require.Len(result.Conflicts, 1)
+
+	// The most recent edit is kept and the other edit is a conflict copy
+	require.Equal("# Note 1\n\nlocal\ntwo\nTHREE\n", manager.GetNote("note-1").Content)
+	conflict := manager.GetNote(result.Conflicts[0])
+	require.NotNil(conflict)
+	require.Equal("# Note 1\n\npeer\ntwo\nTHREE\n", conflict.Content)
+}
+
+// Test syncing notes through a bare git repository
+func TestSyncGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+
+	remote := "testing/dirty/remote.git"
+	require.Nil(exec.Command("git", "init", "--quiet", "--bare", remote).Run())
+
+	// Push a note to the repository
+	require.Nil(manager.CreateNote("note-1"))
+	result, err := manager.Sync(remote)
+	require.Nil(err)
+	require.Equal([]string{"note-1"}, result.Pushed)
+
+	// Pull the note on the peer
+	peer, restore := syncTestPeer(t, remote)
+	defer restore()
+	result, err = peer.Sync(remote)
+	require.Nil(err)
+	require.Equal([]string{"note-1"}, result.Pulled)
+	require.Equal("# Note 1\n\n", peer.GetNote("note-1").Content)
+}
+
+// Test syncing an encrypted note, which stays encrypted on the remote
+func TestSyncEncrypted(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	manager.Passphrase = testPassphrase("correct horse")
+	remote := "testing/dirty/remote"
+
+	// Push an encrypted note to the remote
+	require.Nil(manager.CreateNote("secret"))
+	require.Nil(manager.UpdateNote("secret", "hidden words\n"))
+	require.Nil(manager.EncryptNote("secret"))
+
+	_, err = manager.Sync(remote)
+	require.Nil(err)
+
+	file, err := os.ReadFile(path.Join(remote, "entries/secret.md.enc"))
+	require.Nil(err)
+	require.NotContains(string(file), "hidden words")
+
+	// Pull and decrypt the note on the peer
+	peer, restore := syncTestPeer(t, remote)
+	defer restore()
+	peer.Passphrase = testPassphrase("correct horse")
+
+	result, err := peer.Sync(remote)
+	require.Nil(err)
+	require.Equal([]string{"secret"}, result.Pulled)
+	require.True(peer.GetNote("secret").Encrypted)
+	require.Equal("", peer.GetNote("secret").Content)
+
+	require.Nil(peer.DecryptNote("secret"))
+	require.Equal("hidden words\n", peer.GetNote("secret").Content)
+}
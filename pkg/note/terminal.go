@@ -0,0 +1,362 @@
+package note
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/quick"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+	"golang.org/x/text/width"
+)
+
+// Matches ANSI styling and OSC 8 hyperlink sequences, which take up no space
+// on the terminal
+var escapeMatcher = regexp.MustCompile("\x1b\\[[0-9;]*m|\x1b\\]8;;[^\x1b]*\x1b\\\\")
+
+// Style used to highlight code blocks
+const codeStyle = "monokai"
+
+// terminalRenderer renders the markdown AST of a note as text for a terminal
+type terminalRenderer struct {
+	source []byte // Markdown source the AST refers to
+	color  bool   // Whether to use ANSI styling and hyperlinks
+}
+
+// Generate and return a representation of the note for a terminal. Paragraphs
+// are wrapped to the provided width. If color is set, headings, emphasis,
+// code, and links are styled with ANSI escape sequences and links can be
+// clicked. Otherwise the output is plain text
+func (a *Note) AsTerminal(width int, color bool) string {
+	if width < 20 {
+		width = 20
+	}
+
+	source := []byte(a.Content)
+	r := &terminalRenderer{source: source, color: color}
+
+	document := markdown.Parser().Parse(text.NewReader(source))
+	lines := r.blocks(document, width)
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Wrap text in ANSI styling, where off resets only the provided style so it
+// can be nested in other styles
+func (r *terminalRenderer) style(on string, off string, s string) string {
+	if !r.color || s == "" {
+		return s
+	}
+
+	return "\x1b[" + on + "m" + s + "\x1b[" + off + "m"
+}
+
+// Render the block children of a node as lines, separated by blank lines
+// unless they are in a tight list
+func (r *terminalRenderer) blocks(parent ast.Node, width int) []string {
+	tight := false
+	if parent.Kind() == ast.KindListItem {
+		if list, ok := parent.Parent().(*ast.List); ok {
+			tight = list.IsTight
+		}
+	}
+
+	lines := []string{}
+	for child := parent.FirstChild(); child != nil; child = child.NextSibling() {
+		if len(lines) > 0 && !tight {
+			lines = append(lines, "")
+		}
+		lines = append(lines, r.block(child, width)...)
+	}
+
+	return lines
+}
+
+// Render a single block node as lines no wider than the width where possible
+func (r *terminalRenderer) block(node ast.Node, width int) []string {
+	switch n := node.(type) {
+	case *ast.Heading:
+		heading := strings.Repeat("#", n.Level) + " " + r.inline(n)
+		switch n.Level {
+		case 1:
+			heading = r.style("1;4;35", "22;24;39", heading)
+		case 2:
+			heading = r.style("1;36", "22;39", heading)
+		default:
+			heading = r.style("1", "22", heading)
+		}
+		return wrap(heading, width)
+
+	case *ast.Paragraph, *ast.TextBlock:
+		return wrap(r.inline(n), width)
+
+	case *ast.Blockquote:
+		prefix := r.style("2", "22", "│ ")
+		if !r.color {
+			prefix = "> "
+		}
+		return indent(r.blocks(n, width-2), prefix, prefix)
+
+	case *ast.List:
+		lines := []string{}
+		number := n.Start
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			if len(lines) > 0 && !n.IsTight {
+				lines = append(lines, "")
+			}
+
+			marker := "• "
+			if n.IsOrdered() {
+				marker = fmt.Sprintf("%d. ", number)
+				number++
+			}
+
+			padding := strings.Repeat(" ", visibleWidth(marker))
+			lines = append(lines, indent(r.blocks(item, width-len(padding)), r.style("33", "39", marker), padding)...)
+		}
+		return lines
+
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		code := strings.Builder{}
+		for i := 0; i < n.Lines().Len(); i++ {
+			segment := n.Lines().At(i)
+			code.Write(segment.Value(r.source))
+		}
+
+		language := ""
+		if fenced, ok := n.(*ast.FencedCodeBlock); ok {
+			language = string(fenced.Language(r.source))
+		}
+
+		return indent(strings.Split(strings.TrimRight(r.highlight(code.String(), language), "\n"), "\n"), "  ", "  ")
+
+	case *ast.ThematicBreak:
+		return []string{r.style("2", "22", strings.Repeat("─", width))}
+
+	case *ast.HTMLBlock:
+		lines := []string{}
+		for i := 0; i < n.Lines().Len(); i++ {
+			segment := n.Lines().At(i)
+			lines = append(lines, strings.TrimRight(string(segment.Value(r.source)), "\n"))
+		}
+		return lines
+
+	case *east.Table:
+		return r.table(n)
+	}
+
+	return r.blocks(node, width)
+}
+
+// Highlight code with the lexer for its language. Code is returned unchanged
+// without color or if it cannot be highlighted
+func (r *terminalRenderer) highlight(code string, language string) string {
+	if !r.color {
+		return code
+	}
+
+	output := strings.Builder{}
+	if err := quick.Highlight(&output, code, language, "terminal256", codeStyle); err != nil {
+		return code
+	}
+
+	return output.String()
+}
+
+// Render a table with aligned columns
+func (r *terminalRenderer) table(table *east.Table) []string {
+	rows := [][]string{}
+	widths := []int{}
+
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		cells := []string{}
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			content := r.inline(cell)
+			if row.Kind() == east.KindTableHeader {
+				content = r.style("1", "22", content)
+			}
+
+			if len(cells) >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := visibleWidth(content); w > widths[len(cells)] {
+				widths[len(cells)] = w
+			}
+
+			cells = append(cells, content)
+		}
+		rows = append(rows, cells)
+	}
+
+	lines := []string{}
+	for i, cells := range rows {
+		padded := []string{}
+		for j, cell := range cells {
+			padding := strings.Repeat(" ", widths[j]-visibleWidth(cell))
+			if j < len(table.Alignments) && table.Alignments[j] == east.AlignRight {
+				padded = append(padded, padding+cell)
+			} else if j < len(table.Alignments) && table.Alignments[j] == east.AlignCenter {
+				padded = append(padded, padding[:len(padding)/2]+cell+padding[len(padding)/2:])
+			} else {
+				padded = append(padded, cell+padding)
+			}
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(padded, r.style("2", "22", " │ ")), " "))
+
+		// Separate the header from the rest of the rows
+		if i == 0 {
+			separators := []string{}
+			for _, w := range widths {
+				separators = append(separators, strings.Repeat("─", w))
+			}
+			lines = append(lines, r.style("2", "22", strings.Join(separators, "─┼─")))
+		}
+	}
+
+	return lines
+}
+
+// Render the inline children of a node as a single string
+func (r *terminalRenderer) inline(parent ast.Node) string {
+	output := strings.Builder{}
+
+	for child := parent.FirstChild(); child != nil; child = child.NextSibling() {
+		switch n := child.(type) {
+		case *ast.Text:
+			output.Write(n.Segment.Value(r.source))
+			if n.HardLineBreak() {
+				output.WriteString("\n")
+			} else if n.SoftLineBreak() {
+				output.WriteString(" ")
+			}
+
+		case *ast.String:
+			output.Write(n.Value)
+
+		case *ast.CodeSpan:
+			code := r.inline(n)
+			if r.color {
+				output.WriteString(r.style("36", "39", code))
+			} else {
+				output.WriteString("`" + code + "`")
+			}
+
+		case *ast.Emphasis:
+			if n.Level >= 2 {
+				output.WriteString(r.style("1", "22", r.inline(n)))
+			} else {
+				output.WriteString(r.style("3", "23", r.inline(n)))
+			}
+
+		case *ast.Link:
+			output.WriteString(r.link(r.inline(n), string(n.Destination)))
+
+		case *ast.AutoLink:
+			output.WriteString(r.link(string(n.Label(r.source)), string(n.URL(r.source))))
+
+		case *ast.Image:
+			output.WriteString(r.link("[image: "+r.inline(n)+"]", string(n.Destination)))
+
+		case *ast.RawHTML:
+			for i := 0; i < n.Segments.Len(); i++ {
+				segment := n.Segments.At(i)
+				output.Write(segment.Value(r.source))
+			}
+
+		case *east.Strikethrough:
+			if r.color {
+				output.WriteString(r.style("9", "29", r.inline(n)))
+			} else {
+				output.WriteString("~~" + r.inline(n) + "~~")
+			}
+
+		case *east.TaskCheckBox:
+			if n.IsChecked {
+				output.WriteString("[x] ")
+			} else {
+				output.WriteString("[ ] ")
+			}
+
+		default:
+			output.WriteString(r.inline(n))
+		}
+	}
+
+	return output.String()
+}
+
+// Render a link, which can be clicked with color and is followed by its
+// destination otherwise
+func (r *terminalRenderer) link(label string, destination string) string {
+	if !r.color {
+		if label == destination || destination == "" {
+			return label
+		}
+		return label + " (" + destination + ")"
+	}
+
+	return "\x1b]8;;" + destination + "\x1b\\" + r.style("4;34", "24;39", label) + "\x1b]8;;\x1b\\"
+}
+
+// Return the number of terminal columns text takes up
+func visibleWidth(s string) int {
+	columns := 0
+	for _, r := range escapeMatcher.ReplaceAllString(s, "") {
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			columns += 2
+		default:
+			columns++
+		}
+	}
+
+	return columns
+}
+
+// Wrap text to lines no wider than the width, keeping explicit line breaks.
+// Words wider than the width are placed on their own line
+func wrap(s string, width int) []string {
+	lines := []string{}
+
+	for _, paragraph := range strings.Split(s, "\n") {
+		line, lineWidth := "", 0
+		for _, word := range strings.Fields(paragraph) {
+			wordWidth := visibleWidth(word)
+			if lineWidth > 0 && lineWidth+1+wordWidth > width {
+				lines = append(lines, line)
+				line, lineWidth = "", 0
+			}
+
+			if lineWidth > 0 {
+				line += " "
+				lineWidth++
+			}
+			line += word
+			lineWidth += wordWidth
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// Prefix the first line with first and every other line with rest
+func indent(lines []string, first string, rest string) []string {
+	indented := []string{}
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+
+		if line == "" {
+			indented = append(indented, strings.TrimRight(prefix, " "))
+		} else {
+			indented = append(indented, prefix+line)
+		}
+	}
+
+	return indented
+}
@@ -0,0 +1,55 @@
+package note_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test rendering a note as plain text for a terminal
+func TestNoteAsTerminalPlain(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	n := &note.Note{Content: "# Title\n\nSome **bold** text with `code` and a [link](https://example.com) in a paragraph that wraps.\n\n- one\n- two\n\n> quote\n\n```go\nx := 1\n```\n\n| A | B |\n|---|---|\n| 1 | 22 |\n"}
+
+	output := n.AsTerminal(30, false)
+	require.NotContains(output, "\x1b")
+	require.Equal(`# Title
+
+Some bold text with `+"`code`"+` and
+a link (https://example.com)
+in a paragraph that wraps.
+
+• one
+• two
+
+> quote
+
+  x := 1
+
+A │ B
+──┼───
+1 │ 22
+`, output)
+
+	// No line is wider than the width unless it is a single word
+	for _, line := range strings.Split(output, "\n") {
+		if len([]rune(line)) > 30 {
+			require.NotContains(strings.TrimSpace(line), " ")
+		}
+	}
+}
+
+// Test rendering a note with styling and hyperlinks for a terminal
+func TestNoteAsTerminalColor(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	n := &note.Note{Content: "# Title\n\nA [link](https://example.com).\n\n```go\nx := 1\n```\n"}
+
+	output := n.AsTerminal(80, true)
+	require.Contains(output, "\x1b[1;4;35m# Title")
+	require.Contains(output, "\x1b]8;;https://example.com\x1b\\")
+	require.NotContains(output, "```")
+}
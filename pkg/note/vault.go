@@ -0,0 +1,223 @@
+package note
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Name of the marker file that selects a vault for a directory tree
+const VaultMarker = ".note"
+
+// Environment variable that selects a vault
+const VaultEnv = "NOTE_VAULT"
+
+// Only allow a-z, A-Z, 0-9, '-', and '_' for valid vault names
+var vaultNameMatcher = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Vault is a named set of notes with its own directory, manager file, and
+// config. Vaults without a root use the default paths in the user's home
+// directory
+type Vault struct {
+	Name string `json:"name"` // Name of the vault
+	Root string `json:"root"` // Directory containing the vault's config, manager file, and notes
+}
+
+// DefaultVault is the vault used when no other vault is selected
+var DefaultVault = Vault{Name: "default"}
+
+// Return the note directory, config path, and manager path of the vault. The
+// paths of the default vault are empty if the home directory is unknown
+func (v Vault) Paths() (string, string, string) {
+	if v.Root == "" {
+		if homePath == "" {
+			return "", "", ""
+		}
+
+		return path.Join(homePath, ".local/share/notes/entries/"),
+			path.Join(homePath, ".config/note.json"),
+			path.Join(homePath, ".local/share/notes/manager.json")
+	}
+
+	return path.Join(v.Root, "entries"), path.Join(v.Root, "config.json"), path.Join(v.Root, "manager.json")
+}
+
+// UseVault points the package's paths at the provided vault, so managers
+// returned by GetManager load the vault's notes
+func UseVault(v Vault) {
+	slog.Debug("using vault", "vault", v.Name)
+
+	defaultDirectoryPath, configPath, managerPath = v.Paths()
+}
+
+// VaultRegistry stores the named vaults and the vault currently in use
+type VaultRegistry struct {
+	Current string  `json:"current,omitempty"` // Name of the vault in use when no other vault is selected
+	Vaults  []Vault `json:"vaults"`            // Registered vaults
+}
+
+// Load the vault registry, which is empty if it hasn't been saved yet
+func LoadVaults() (*VaultRegistry, error) {
+	registry := &VaultRegistry{Vaults: []Vault{}}
+
+	file, err := os.ReadFile(vaultsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return registry, nil
+	} else if err != nil {
+		slog.Error("failed to read vault registry", "err", err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal(file, registry); err != nil {
+		slog.Error("failed to parse vault registry", "err", err)
+		return nil, err
+	}
+
+	return registry, nil
+}
+
+// Save the vault registry
+func (r *VaultRegistry) Save() error {
+	file, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		slog.Error("failed to marshal vault registry", "err", err)
+		return err
+	}
+
+	if err := os.MkdirAll(path.Dir(vaultsPath), 0755); err != nil {
+		slog.Error("failed to create vault registry directory", "err", err)
+		return err
+	}
+
+	if err := os.WriteFile(vaultsPath, file, 0600); err != nil {
+		slog.Error("failed to save vault registry", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+// Return every vault, including the default vault, sorted by name
+func (r *VaultRegistry) List() []Vault {
+	registered := append([]Vault{}, r.Vaults...)
+	sort.SliceStable(registered, func(i, j int) bool {
+		return registered[i].Name < registered[j].Name
+	})
+
+	return append([]Vault{DefaultVault}, registered...)
+}
+
+// Return the vault with the provided name
+func (r *VaultRegistry) Get(name string) (Vault, error) {
+	if name == DefaultVault.Name {
+		return DefaultVault, nil
+	}
+
+	for _, vault := range r.Vaults {
+		if vault.Name == name {
+			return vault, nil
+		}
+	}
+
+	return Vault{}, wrapf(ErrNotFound, "vault with name '%s' not found", name)
+}
+
+// Register a vault with a root directory, which must be an absolute path. The
+// registry is not saved
+func (r *VaultRegistry) Add(name string, root string) (Vault, error) {
+	slog.Info("adding vault", "name", name, "root", root)
+
+	if !vaultNameMatcher.MatchString(name) {
+		return Vault{}, wrapf(ErrInvalidName, "invalid vault name '%s'", name)
+	}
+	if _, err := r.Get(name); err == nil {
+		return Vault{}, wrapf(ErrDuplicate, "duplicate vault name '%s'", name)
+	}
+	if !path.IsAbs(root) {
+		return Vault{}, fmt.Errorf("vault root '%s' is not an absolute path", root)
+	}
+
+	vault := Vault{Name: name, Root: path.Clean(root)}
+	r.Vaults = append(r.Vaults, vault)
+
+	return vault, nil
+}
+
+// Set the vault used when no other vault is selected. The registry is not saved
+func (r *VaultRegistry) Use(name string) error {
+	if _, err := r.Get(name); err != nil {
+		return err
+	}
+
+	r.Current = name
+	return nil
+}
+
+// Return the path of the closest vault marker in the directory or its parents,
+// or an empty string if there is none
+func FindVaultMarker(directory string) string {
+	for {
+		marker := path.Join(directory, VaultMarker)
+		if info, err := os.Stat(marker); err == nil && !info.IsDir() {
+			return marker
+		}
+
+		parent := path.Dir(directory)
+		if parent == directory {
+			return ""
+		}
+		directory = parent
+	}
+}
+
+// Return the vault named by a marker file. A marker containing a vault name
+// selects that vault, and an empty marker makes its directory a vault
+func (r *VaultRegistry) markerVault(marker string) (Vault, error) {
+	content, err := os.ReadFile(marker)
+	if err != nil {
+		return Vault{}, err
+	}
+
+	name := strings.TrimSpace(string(content))
+	if name == "" {
+		directory := path.Dir(marker)
+		return Vault{Name: path.Base(directory), Root: directory}, nil
+	}
+
+	vault, err := r.Get(name)
+	if err != nil {
+		return Vault{}, fmt.Errorf("%w (named by '%s')", err, marker)
+	}
+
+	return vault, nil
+}
+
+// Resolve the vault to use. The vault named by the provided name is used
+// first, then the vault named by $NOTE_VAULT, then the closest vault marker
+// in the working directory or its parents, then the current vault, and
+// finally the default vault
+func (r *VaultRegistry) Resolve(name string, directory string) (Vault, error) {
+	if name != "" {
+		return r.Get(name)
+	}
+
+	if env := strings.TrimSpace(os.Getenv(VaultEnv)); env != "" {
+		return r.Get(env)
+	}
+
+	if marker := FindVaultMarker(directory); marker != "" {
+		return r.markerVault(marker)
+	}
+
+	if r.Current != "" {
+		return r.Get(r.Current)
+	}
+
+	return DefaultVault, nil
+}
@@ -0,0 +1,147 @@
+package note_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Setup before each test by pointing the vault registry at a temporary file
+func vaultTestSetup(t *testing.T) *note.VaultRegistry {
+	note.ModifyVaultsPath(path.Join(t.TempDir(), "vaults.json"))
+	t.Setenv(note.VaultEnv, "")
+
+	registry, err := note.LoadVaults()
+	require.Nil(t, err)
+
+	return registry
+}
+
+// Test adding, listing, and saving vaults
+func TestVaultRegistry(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	registry := vaultTestSetup(t)
+	root := t.TempDir()
+
+	_, err := registry.Add("work", path.Join(root, "work"))
+	require.Nil(err)
+	_, err = registry.Add("personal", path.Join(root, "personal"))
+	require.Nil(err)
+
+	// Invalid vaults are rejected
+	_, err = registry.Add("work", path.Join(root, "other"))
+	require.Equal("duplicate vault name 'work'", err.Error())
+	_, err = registry.Add("default", path.Join(root, "other"))
+	require.NotNil(err)
+	_, err = registry.Add("$bad", path.Join(root, "other"))
+	require.NotNil(err)
+	_, err = registry.Add("relative", "relative/path")
+	require.NotNil(err)
+
+	require.Nil(registry.Use("work"))
+	require.NotNil(registry.Use("missing"))
+	require.Nil(registry.Save())
+
+	// Reload the registry
+	loaded, err := note.LoadVaults()
+	require.Nil(err)
+	require.Equal("work", loaded.Current)
+
+	names := []string{}
+	for _, vault := range loaded.List() {
+		names = append(names, vault.Name)
+	}
+	require.Equal([]string{"default", "personal", "work"}, names)
+}
+
+// Test the order vaults are selected in
+func TestVaultResolve(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	registry := vaultTestSetup(t)
+	root := t.TempDir()
+
+	_, err := registry.Add("work", path.Join(root, "work"))
+	require.Nil(err)
+	_, err = registry.Add("personal", path.Join(root, "personal"))
+	require.Nil(err)
+
+	project := path.Join(root, "project", "src")
+	require.Nil(os.MkdirAll(project, 0755))
+
+	// Without anything selected, the default vault is used
+	vault, err := registry.Resolve("", project)
+	require.Nil(err)
+	require.Equal(note.DefaultVault, vault)
+
+	// The current vault is used next
+	require.Nil(registry.Use("personal"))
+	vault, err = registry.Resolve("", project)
+	require.Nil(err)
+	require.Equal("personal", vault.Name)
+
+	// A marker naming a vault in a parent directory takes precedence
+	require.Nil(os.WriteFile(path.Join(root, "project", ".note"), []byte("work\n"), 0644))
+	vault, err = registry.Resolve("", project)
+	require.Nil(err)
+	require.Equal("work", vault.Name)
+
+	// An empty marker makes its directory a vault
+	require.Nil(os.WriteFile(path.Join(project, ".note"), []byte{}, 0644))
+	vault, err = registry.Resolve("", project)
+	require.Nil(err)
+	require.Equal(note.Vault{Name: "src", Root: project}, vault)
+
+	// The environment takes precedence over markers
+	t.Setenv(note.VaultEnv, "personal")
+	vault, err = registry.Resolve("", project)
+	require.Nil(err)
+	require.Equal("personal", vault.Name)
+
+	// An explicit name takes precedence over everything
+	vault, err = registry.Resolve("work", project)
+	require.Nil(err)
+	require.Equal("work", vault.Name)
+
+	_, err = registry.Resolve("missing", project)
+	require.Equal("vault with name 'missing' not found", err.Error())
+}
+
+// Test that managers load the notes of the vault in use
+func TestUseVault(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	registry := vaultTestSetup(t)
+	root := t.TempDir()
+
+	work, err := registry.Add("work", path.Join(root, "work"))
+	require.Nil(err)
+	personal, err := registry.Add("personal", path.Join(root, "personal"))
+	require.Nil(err)
+
+	// Create a note in each vault
+	note.UseVault(work)
+	manager, err := note.GetManager()
+	require.Nil(err)
+	manager.Config.Editor = "cat"
+	require.Nil(manager.CreateNote("work-note"))
+
+	note.UseVault(personal)
+	manager, err = note.GetManager()
+	require.Nil(err)
+	require.Empty(manager.Notes)
+	require.Equal(path.Join(root, "personal", "entries"), manager.Config.Directory)
+
+	// The work vault keeps its own notes
+	note.UseVault(work)
+	manager, err = note.GetManager()
+	require.Nil(err)
+	require.Len(manager.Notes, 1)
+
+	_, err = os.Stat(path.Join(root, "work", "entries", "work-note.md"))
+	require.Nil(err)
+}
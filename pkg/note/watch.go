@@ -0,0 +1,310 @@
+package note
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScanResult lists the notes whose files changed outside the manager
+type ScanResult struct {
+	Created []string          // Notes added for new files
+	Updated []string          // Notes whose files were modified
+	Renamed map[string]string // New filenames of notes whose files were renamed, keyed by their old filename
+	Deleted []string          // Notes removed because their files were deleted
+}
+
+// Return whether the scan found any changes
+func (r *ScanResult) changed() bool {
+	return len(r.Created)+len(r.Updated)+len(r.Renamed)+len(r.Deleted) > 0
+}
+
+// Time a note file can be modified after its note's UpdatedAt and still count
+// as written by the manager, which sets UpdatedAt around the time it writes
+const modTimeTolerance = time.Second
+
+// Size and modification time of a note file when it was last scanned
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// A note file found in the note directory
+type scannedFile struct {
+	encrypted bool
+	info      fs.FileInfo
+}
+
+// Return the note files in the note directory, keyed by filename. Files with
+// names that aren't valid note names are ignored
+func (m *Manager) noteFiles() (map[string][]scannedFile, error) {
+	names, err := m.files().ReadDir(m.Directory())
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]scannedFile{}
+	for _, name := range names {
+		filename, encrypted := strings.CutSuffix(name, ".md.enc")
+		if !encrypted {
+			var ok bool
+			if filename, ok = strings.CutSuffix(name, ".md"); !ok {
+				continue
+			}
+		}
+		if !filenameMatcher.MatchString(filename) || filename != strings.ToLower(filename) {
+			continue
+		}
+
+		info, err := m.files().Stat(path.Join(m.Directory(), name))
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		files[filename] = append(files[filename], scannedFile{encrypted: encrypted, info: info})
+	}
+
+	return files, nil
+}
+
+// Return the time a file was modified, or the current time if it is unknown
+func (m *Manager) modTime(info fs.FileInfo) time.Time {
+	if info.ModTime().IsZero() {
+		return m.now()
+	}
+	return info.ModTime()
+}
+
+// Scan compares the note directory with the manager and applies changes made
+// to note files outside the manager, such as by other editors, sync clients,
+// or 'git pull'. Modified files update their note's content, fields, and
+// UpdatedAt, new files become notes, and notes whose files were deleted are removed. A
+// deleted note whose content reappears under another name is treated as
+// renamed if its content was loaded. Subscribers are sent an event for every change, and the manager
+// file is saved if anything changed
+func (m *Manager) Scan() (*ScanResult, error) {
+	m.log().Debug("scanning note directory", "directory", m.Directory())
+
+	// A missing directory only means there are no notes yet if the manager
+	// has none, otherwise it may be a drive that isn't mounted
+	files, err := m.noteFiles()
+	if errors.Is(err, fs.ErrNotExist) && len(m.Notes) == 0 {
+		return &ScanResult{Renamed: map[string]string{}}, nil
+	} else if err != nil {
+		m.log().Error("failed to read note directory", "err", err)
+		return nil, err
+	}
+
+	if m.scanned == nil {
+		m.scanned = map[string]fileState{}
+	}
+
+	result := &ScanResult{Renamed: map[string]string{}}
+	events := []Event{}
+	missing := []*Note{}
+
+	// Check the file of every note
+	for _, note := range m.Notes {
+		var file *scannedFile
+		for i := range files[note.Filename] {
+			if files[note.Filename][i].encrypted == note.Encrypted {
+				file = &files[note.Filename][i]
+			}
+		}
+		delete(files, note.Filename)
+
+		if file == nil {
+			missing = append(missing, note)
+			continue
+		}
+
+		// Skip files that haven't changed since the last scan
+		state := fileState{size: file.info.Size(), modTime: file.info.ModTime()}
+		previous, seen := m.scanned[note.Filename]
+		m.scanned[note.Filename] = state
+		if seen && previous.size == state.size && previous.modTime.Equal(state.modTime) {
+			continue
+		}
+
+		old := note.Metadata
+		if note.Encrypted {
+			// Encrypted content can't be compared, so only count writes made
+			// after the manager last updated the note
+			if !seen || !file.info.ModTime().After(note.UpdatedAt) {
+				continue
+			}
+		} else if note.unloaded {
+			// Content that was never loaded can't be compared either, so the
+			// first scan only counts writes made after the manager last
+			// updated the note
+			if !seen && !file.info.ModTime().After(note.UpdatedAt.Add(modTimeTolerance)) {
+				continue
+			}
+			if err := m.LoadContent([]*Note{note}); err != nil {
+				return nil, err
+			}
+		} else {
+			cached, ok := m.cache[note.Filename]
+			read := m.readContent(m.NotePath(note.Filename), cached, ok)
+			if read.err != nil {
+				m.log().Error("failed to read note file", "filename", note.Filename, "err", read.err)
+				return nil, read.err
+			}
+			m.cacheContent(note.Filename, read.cached)
+
+			if read.cached.content == note.Content {
+				continue
+			}
+			note.Content = read.cached.content
+		}
+
+		// Front matter written outside the manager updates the note's fields
+		note.syncFields()
+
+		m.log().Info("note file was modified", "filename", note.Filename)
+		note.UpdatedAt = m.modTime(file.info)
+		result.Updated = append(result.Updated, note.Filename)
+		events = append(events, Event{Type: EventUpdated, Filename: note.Filename, Old: &old})
+	}
+
+	// Add notes for new files, matching renamed files by their content
+	filenames := []string{}
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	added := []*Note{}
+	for _, filename := range filenames {
+		file := files[filename][0]
+		note := &Note{Metadata: Metadata{
+			Filename:  filename,
+			Author:    m.Config.DefaultAuthor,
+			CreatedAt: m.modTime(file.info),
+			UpdatedAt: m.modTime(file.info),
+			Encrypted: file.encrypted,
+		}}
+
+		if !file.encrypted {
+			read := m.readContent(path.Join(m.Directory(), filename+".md"), cachedContent{}, false)
+			if read.err != nil {
+				m.log().Error("failed to read note file", "filename", filename, "err", read.err)
+				return nil, read.err
+			}
+			m.cacheContent(filename, read.cached)
+			note.Content = read.cached.content
+			note.syncFields()
+
+			// A missing note with the same content was renamed
+			for i, renamed := range missing {
+				if renamed.Encrypted || renamed.unloaded || renamed.Content != note.Content {
+					continue
+				}
+
+				m.log().Info("note file was renamed", "from", renamed.Filename, "to", filename)
+				old := renamed.Metadata
+				delete(m.scanned, renamed.Filename)
+				renamed.Filename = filename
+				missing = append(missing[:i], missing[i+1:]...)
+
+				result.Renamed[old.Filename] = filename
+				events = append(events, Event{Type: EventRenamed, Filename: filename, Old: &old})
+				note = nil
+				break
+			}
+		}
+
+		m.scanned[filename] = fileState{size: file.info.Size(), modTime: file.info.ModTime()}
+		if note == nil {
+			continue
+		}
+
+		m.log().Info("note file was created", "filename", filename)
+		added = append(added, note)
+		result.Created = append(result.Created, filename)
+		events = append(events, Event{Type: EventCreated, Filename: filename})
+	}
+	m.Notes = append(m.Notes, added...)
+
+	// Remove notes whose files were deleted
+	for _, note := range missing {
+		m.log().Info("note file was deleted", "filename", note.Filename)
+		_, index := m.contains(note.Filename)
+		m.Notes = append(m.Notes[:index], m.Notes[index+1:]...)
+		delete(m.scanned, note.Filename)
+
+		old := note.Metadata
+		result.Deleted = append(result.Deleted, note.Filename)
+		events = append(events, Event{Type: EventDeleted, Filename: note.Filename, Old: &old})
+	}
+
+	if !result.changed() {
+		return result, nil
+	}
+
+	// The files are already up to date, so only the metadata is saved
+	if err := m.saveMetadata(); err != nil {
+		m.log().Error("failed to save manager", "err", err)
+		return nil, err
+	}
+
+	for _, event := range events {
+		if event.Type != EventDeleted {
+			current := m.GetNote(event.Filename).Metadata
+			event.New = &current
+		}
+		m.emit(event)
+	}
+
+	return result, nil
+}
+
+// Watch scans the note directory every interval until the context is done,
+// keeping the manager in sync with changes made outside of it. If a lock is
+// provided, it is held during each scan so the manager can be shared with
+// other goroutines. Failed scans are logged and retried at the next interval.
+// An error is only returned if the interval isn't positive
+func (m *Manager) Watch(ctx context.Context, interval time.Duration, lock sync.Locker) error {
+	if interval <= 0 {
+		m.log().Error("invalid watch interval", "interval", interval)
+		return wrapf(ErrInvalidConfig, "watch interval must be positive, not %s", interval)
+	}
+
+	m.log().Info("watching note directory", "directory", m.Directory(), "interval", interval)
+
+	// Renamed files are matched by content, so every note's content is loaded
+	if lock != nil {
+		lock.Lock()
+	}
+	m.loadAll()
+	if lock != nil {
+		lock.Unlock()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if lock != nil {
+			lock.Lock()
+		}
+		_, err := m.Scan()
+		if lock != nil {
+			lock.Unlock()
+		}
+		if err != nil {
+			m.log().Warn("failed to scan note directory", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package note_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that scans apply files created, modified, renamed, and deleted outside the manager
+func TestScan(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	store := &note.MemoryStore{}
+	manager, err := note.New(
+		note.WithConfigPath("/vault/config.json"),
+		note.WithManagerPath("/vault/manager.json"),
+		note.WithDirectory("/vault/entries"),
+		note.WithStore(store),
+	)
+	require.Nil(err)
+	manager.Config.DefaultAuthor = "Ethan"
+
+	require.Nil(manager.CreateNote("note-1"))
+	require.Nil(manager.CreateNote("note-2"))
+	require.Nil(manager.CreateNote("note-3"))
+
+	// Changes made by the manager aren't reported
+	result, err := manager.Scan()
+	require.Nil(err)
+	require.Equal(&note.ScanResult{Renamed: map[string]string{}}, result)
+
+	events := []note.Event{}
+	manager.OnChange(func(e note.Event) {
+		events = append(events, e)
+	})
+
+	// Change the files outside the manager
+	before := manager.GetNote("note-1").Metadata
+	require.Nil(store.WriteFile("/vault/entries/note-1.md", []byte("# Edited\n"), 0600))
+	content, err := store.ReadFile("/vault/entries/note-2.md")
+	require.Nil(err)
+	require.Nil(store.WriteFile("/vault/entries/renamed.md", content, 0600))
+	require.Nil(store.Remove("/vault/entries/note-2.md"))
+	require.Nil(store.Remove("/vault/entries/note-3.md"))
+	require.Nil(store.WriteFile("/vault/entries/new-note.md", []byte("# New\n"), 0600))
+	require.Nil(store.WriteFile("/vault/entries/Not A Note.md", []byte(""), 0600))
+
+	result, err = manager.Scan()
+	require.Nil(err)
+	require.Equal(&note.ScanResult{
+		Created: []string{"new-note"},
+		Updated: []string{"note-1"},
+		Renamed: map[string]string{"note-2": "renamed"},
+		Deleted: []string{"note-3"},
+	}, result)
+
+	// The manager matches the files
+	require.Equal("# Edited\n", manager.GetNote("note-1").Content)
+	require.True(manager.GetNote("note-1").UpdatedAt.After(before.UpdatedAt))
+	require.Nil(manager.GetNote("note-2"))
+	require.Nil(manager.GetNote("note-3"))
+	require.Equal("Ethan", manager.GetNote("new-note").Author)
+	require.Equal("# New\n", manager.GetNote("new-note").Content)
+
+	// Subscribers were told about every change
+	require.Len(events, 4)
+	require.Equal(note.EventUpdated, events[0].Type)
+	require.Equal(before, *events[0].Old)
+	require.Equal(note.EventCreated, events[1].Type)
+	require.Equal(note.EventRenamed, events[2].Type)
+	require.Equal("note-2", events[2].Old.Filename)
+	require.Equal("renamed", events[2].New.Filename)
+	require.Equal(events[2].Old.CreatedAt, events[2].New.CreatedAt)
+	require.Equal(note.EventDeleted, events[3].Type)
+
+	// The changes were saved
+	reloaded, err := note.New(
+		note.WithConfigPath("/vault/config.json"),
+		note.WithManagerPath("/vault/manager.json"),
+		note.WithStore(store),
+	)
+	require.Nil(err)
+	require.Len(reloaded.GetNotes(), 3)
+	require.NotNil(reloaded.GetNote("renamed"))
+
+	// Nothing changed since the last scan
+	result, err = manager.Scan()
+	require.Nil(err)
+	require.Equal(&note.ScanResult{Renamed: map[string]string{}}, result)
+}
+
+// Test that scans update fields from front matter written outside the manager
+func TestScanFields(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, store := fieldsTestSetup(require)
+	require.Nil(manager.CreateNote("note-1"))
+
+	_, err := manager.Scan()
+	require.Nil(err)
+
+	// Edit the front matter of a note and add a note with front matter
+	require.Nil(store.WriteFile("/vault/entries/note-1.md", []byte("---\nstatus: review\npriority: 2\n---\n\n# Note 1\n"), 0600))
+	require.Nil(store.WriteFile("/vault/entries/new-note.md", []byte("---\nstatus: done\n---\n\n# New\n"), 0600))
+
+	result, err := manager.Scan()
+	require.Nil(err)
+	require.Equal([]string{"note-1"}, result.Updated)
+	require.Equal([]string{"new-note"}, result.Created)
+
+	require.Equal(map[string]any{"status": "review", "priority": 2.0}, manager.GetNote("note-1").Fields)
+	require.Equal(map[string]any{"status": "done"}, manager.GetNote("new-note").Fields)
+
+	// The fields were saved
+	reloaded, err := note.New(
+		note.WithConfigPath("/vault/config.json"),
+		note.WithManagerPath("/vault/manager.json"),
+		note.WithDirectory("/vault/entries"),
+		note.WithStore(store),
+	)
+	require.Nil(err)
+	require.Equal(map[string]any{"status": "review", "priority": 2.0}, reloaded.GetNote("note-1").Fields)
+}
+
+// Test that watching picks up edits to files on disk until the context is done
+func TestWatch(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, err := managerTestSetup()
+	require.Nil(err)
+	require.Nil(manager.CreateNote("note-1"))
+
+	lock := &sync.Mutex{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- manager.Watch(ctx, 10*time.Millisecond, lock)
+	}()
+
+	require.Nil(os.WriteFile(path.Join(manager.Config.Directory, "note-1.md"), []byte("# Edited\n"), 0600))
+	require.Eventually(func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return manager.GetNote("note-1").Content == "# Edited\n"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.Nil(<-done)
+
+	// Intervals must be positive
+	for _, interval := range []time.Duration{0, -time.Second} {
+		require.True(errors.Is(manager.Watch(context.Background(), interval, nil), note.ErrInvalidConfig))
+	}
+}
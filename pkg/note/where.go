@@ -0,0 +1,405 @@
+package note
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Comparison operators of where conditions, with longer operators first so
+// they are matched before their prefixes
+var whereOperators = []string{"!=", ">=", "<=", "=", ">", "<", "~"}
+
+// Where is a condition on the metadata and custom fields of notes, such as
+// 'status=draft AND priority>2'. Each comparison is a field name, an operator
+// (=, !=, >, >=, <, <=, or ~ for contains), and a value, which can be quoted.
+// A note matches if every comparison joined with AND matches
+type Where struct {
+	comparisons []comparison
+}
+
+// A single comparison of a where condition
+type comparison struct {
+	field string
+	op    string
+	value string
+}
+
+// ParseWhere parses a where condition. An empty condition matches every note
+func ParseWhere(expr string) (*Where, error) {
+	where := &Where{}
+	if strings.TrimSpace(expr) == "" {
+		return where, nil
+	}
+
+	clauses, err := splitClauses(expr)
+	if err != nil {
+		return nil, wrapf(ErrInvalidQuery, "invalid where condition '%s' (%v)", expr, err)
+	}
+
+	for _, clause := range clauses {
+		c, err := parseComparison(clause)
+		if err != nil {
+			return nil, wrapf(ErrInvalidQuery, "invalid where condition '%s' (%v)", expr, err)
+		}
+		where.comparisons = append(where.comparisons, c)
+	}
+
+	return where, nil
+}
+
+// Split a where condition into the comparisons joined by AND, which is
+// matched in any case outside of quotes
+func splitClauses(expr string) ([]string, error) {
+	clauses := []string{}
+	start := 0
+	var quote rune
+
+	for i, r := range expr {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case isWordAt(expr, i, "and"):
+			clauses = append(clauses, expr[start:i])
+			start = i + len("and")
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+
+	return append(clauses, expr[start:]), nil
+}
+
+// Return whether a word, in any case, starts at an index of a string and is
+// preceded by a space and followed by a space or the end of the string
+func isWordAt(s string, i int, word string) bool {
+	end := i + len(word)
+	if i == 0 || end > len(s) || !strings.EqualFold(s[i:end], word) {
+		return false
+	}
+
+	return unicode.IsSpace(rune(s[i-1])) && (end == len(s) || unicode.IsSpace(rune(s[end])))
+}
+
+// Parse a comparison, such as 'priority > 2' or "title~'weekly review'"
+func parseComparison(clause string) (comparison, error) {
+	clause = strings.TrimSpace(clause)
+
+	for i := range clause {
+		for _, op := range whereOperators {
+			if !strings.HasPrefix(clause[i:], op) {
+				continue
+			}
+
+			field := strings.TrimSpace(clause[:i])
+			if !fieldNameMatcher.MatchString(field) {
+				return comparison{}, fmt.Errorf("invalid field name '%s'", field)
+			}
+
+			value := strings.TrimSpace(clause[i+len(op):])
+			if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+				value = value[1 : len(value)-1]
+			}
+
+			return comparison{field: field, op: op, value: value}, nil
+		}
+	}
+
+	if clause == "" {
+		return comparison{}, fmt.Errorf("missing comparison")
+	}
+	return comparison{}, fmt.Errorf("missing operator in '%s'", clause)
+}
+
+// Matches returns whether a note matches every comparison of the condition
+func (w *Where) Matches(note *Note) bool {
+	for _, c := range w.comparisons {
+		if !c.matches(note) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Return whether the condition reads fields that need the content of notes
+func (w *Where) needsContent() bool {
+	for _, c := range w.comparisons {
+		if contentFields[strings.ToLower(c.field)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Fields of notes whose values are read from their content
+var contentFields = map[string]bool{"tags": true, "links": true, "text": true, "content": true, "words": true, "size": true}
+
+// Value returns the value of a field of the note, and whether the note has the
+// field. Fields are the metadata (filename, title, author, createdAt or
+// created, updatedAt or updated, and encrypted), values read from the content
+// (tags, links, words, size, content, and text, which is the title and
+// content), and custom fields. Lists are returned as []any and numbers as
+// float64
+func (a *Note) Value(name string) (any, bool) {
+	switch strings.ToLower(name) {
+	case "filename":
+		return a.Filename, true
+	case "title":
+		return a.Title, true
+	case "author":
+		return a.Author, true
+	case "createdat", "created":
+		return a.CreatedAt, true
+	case "updatedat", "updated":
+		return a.UpdatedAt, true
+	case "encrypted":
+		return a.Encrypted, true
+	case "tags":
+		tags := []any{}
+		for _, tag := range a.Tags() {
+			tags = append(tags, tag)
+		}
+		return tags, true
+	case "links":
+		targets := []any{}
+		for _, link := range a.Links() {
+			targets = append(targets, link.Target)
+		}
+		return targets, true
+	case "words":
+		return float64(a.WordCount()), true
+	case "size":
+		return float64(a.Size()), true
+	case "content":
+		return a.Content, true
+	case "text":
+		return a.Title + "\n" + a.Content, true
+	}
+
+	value, ok := a.Fields[name]
+	return value, ok
+}
+
+// Return whether a note matches the comparison
+func (c comparison) matches(note *Note) bool {
+	value, ok := note.Value(c.field)
+	return c.matchesField(value, ok, time.Now())
+}
+
+// Return whether the value of a field matches the comparison, resolving
+// relative dates from a time. Notes without the field only match '!=' with a
+// value, or '=' without one
+func (c comparison) matchesField(value any, ok bool, now time.Time) bool {
+	if !ok {
+		return c.op == "!=" && c.value != "" || c.op == "=" && c.value == ""
+	}
+
+	// Lists match if any of their items match, or for '!=' if none equal the value
+	if list, ok := value.([]any); ok {
+		if c.op == "!=" {
+			return !(comparison{field: c.field, op: "=", value: c.value}).matchesList(list, now)
+		}
+		return c.matchesList(list, now)
+	}
+
+	return c.matchesValue(value, now)
+}
+
+// Return whether any item of a list matches the comparison
+func (c comparison) matchesList(list []any, now time.Time) bool {
+	for _, item := range list {
+		if c.matchesValue(item, now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Return whether a single value matches the comparison
+func (c comparison) matchesValue(value any, now time.Time) bool {
+	if c.op == "~" {
+		return strings.Contains(strings.ToLower(formatFieldValue(value)), strings.ToLower(c.value))
+	}
+
+	// Relative dates are only resolved for dates, so other text can use the same words
+	text := c.value
+	if t, ok := relativeDate(text, now); ok && isDate(value) {
+		text = t.Format(time.RFC3339Nano)
+	}
+
+	order, ok := compareFieldValue(value, text, now.Location())
+	if !ok {
+		return c.op == "!="
+	}
+
+	switch c.op {
+	case "=":
+		return order == 0
+	case "!=":
+		return order != 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	}
+
+	return false
+}
+
+// Return a field value as text
+func formatFieldValue(value any) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Match relative dates such as '-7d' (7 days ago) or '+2w' (in 2 weeks)
+var relativeDateMatcher = regexp.MustCompile(`^([+-])(\d+)([hdwy])$`)
+
+// Return the time of a relative date, which is 'now', 'today', 'yesterday',
+// 'tomorrow', or a signed number of hours, days, weeks, or years from a time
+func relativeDate(text string, now time.Time) (time.Time, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch strings.ToLower(text) {
+	case "now":
+		return now, true
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	}
+
+	match := relativeDateMatcher.FindStringSubmatch(strings.ToLower(text))
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	n, _ := strconv.Atoi(match[2])
+	if match[1] == "-" {
+		n = -n
+	}
+
+	switch match[3] {
+	case "h":
+		return now.Add(time.Duration(n) * time.Hour), true
+	case "d":
+		return now.AddDate(0, 0, n), true
+	case "w":
+		return now.AddDate(0, 0, 7*n), true
+	default:
+		return now.AddDate(n, 0, 0), true
+	}
+}
+
+// Return whether a value is a time or text written as a date
+func isDate(value any) bool {
+	switch v := value.(type) {
+	case time.Time:
+		return true
+	case string:
+		_, ok := parseDate(v, time.UTC)
+		return ok
+	}
+
+	return false
+}
+
+// Parse a date or time written as YYYY-MM-DD or in RFC 3339. Dates are
+// midnight in the provided location, so they match relative dates resolved there
+func parseDate(text string, loc *time.Location) (time.Time, bool) {
+	if t, err := time.ParseInLocation(dateLayout, text, loc); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339, text); err == nil {
+		return t, true
+	}
+
+	return time.Time{}, false
+}
+
+// Compare a field value with text, returning -1, 0, or 1 as the value is less
+// than, equal to, or greater than the text. Numbers and dates are compared by
+// value and text is compared ignoring case. The second result is false if the
+// text can't be compared with the value, such as a word with a number. Dates
+// without a time are read in the provided location
+func compareFieldValue(value any, text string, loc *time.Location) (int, bool) {
+	switch v := value.(type) {
+	case float64:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return 0, false
+		}
+		return compareNumbers(v, number), true
+
+	case bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return 0, false
+		}
+		if v == b {
+			return 0, true
+		} else if v {
+			return 1, true
+		}
+		return -1, true
+
+	case time.Time:
+		t, ok := parseDate(text, loc)
+		if !ok {
+			return 0, false
+		}
+		return v.Compare(t), true
+
+	case string:
+		a, errA := strconv.ParseFloat(v, 64)
+		b, errB := strconv.ParseFloat(text, 64)
+		if errA == nil && errB == nil {
+			return compareNumbers(a, b), true
+		}
+
+		if a, ok := parseDate(v, loc); ok {
+			if b, ok := parseDate(text, loc); ok {
+				return a.Compare(b), true
+			}
+		}
+
+		return strings.Compare(strings.ToLower(v), strings.ToLower(text)), true
+	}
+
+	return 0, false
+}
+
+// Return -1, 0, or 1 as a is less than, equal to, or greater than b
+func compareNumbers(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
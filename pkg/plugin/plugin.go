@@ -0,0 +1,95 @@
+// Package plugin lets extensions add exporters, front matter fields, CLI
+// commands, and change handlers to the note tool. Extensions register
+// themselves from an init function and are compiled in with a blank import
+package plugin
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/spf13/cobra"
+)
+
+// Plugin is an extension of the note tool
+type Plugin struct {
+	Name        string                  // Unique name of the plugin
+	Exporters   []note.Exporter         // Formats notes can be published in
+	FrontMatter []note.FrontMatterField // Fields added to published markdown notes
+	Commands    []*cobra.Command        // Subcommands added to the 'note' command
+	OnChange    func(note.Event)        // Called when a manager changes a note
+}
+
+// Registered plugins, in the order they were registered
+var (
+	mu      sync.RWMutex
+	plugins = []Plugin{}
+)
+
+// Register adds a plugin, registering its exporters and front matter fields
+// with the note package. An error is returned if the name is empty or already
+// registered, or if one of its exporters or fields can't be registered
+func Register(p Plugin) error {
+	if p.Name == "" {
+		return fmt.Errorf("plugin must have a name")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, registered := range plugins {
+		if registered.Name == p.Name {
+			return fmt.Errorf("%w: duplicate plugin '%s'", note.ErrDuplicate, p.Name)
+		}
+	}
+
+	for _, exporter := range p.Exporters {
+		if err := note.RegisterExporter(exporter); err != nil {
+			return fmt.Errorf("plugin '%s': %w", p.Name, err)
+		}
+	}
+
+	for _, field := range p.FrontMatter {
+		if err := note.RegisterFrontMatterField(field); err != nil {
+			return fmt.Errorf("plugin '%s': %w", p.Name, err)
+		}
+	}
+
+	plugins = append(plugins, p)
+	return nil
+}
+
+// MustRegister is like Register but panics if the plugin can't be registered.
+// It is meant to be called from an extension's init function
+func MustRegister(p Plugin) {
+	if err := Register(p); err != nil {
+		panic(err)
+	}
+}
+
+// Plugins returns every registered plugin, in the order they were registered
+func Plugins() []Plugin {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return append([]Plugin{}, plugins...)
+}
+
+// Commands returns the subcommands of every registered plugin
+func Commands() []*cobra.Command {
+	commands := []*cobra.Command{}
+	for _, p := range Plugins() {
+		commands = append(commands, p.Commands...)
+	}
+
+	return commands
+}
+
+// Attach subscribes the change handler of every registered plugin to a manager
+func Attach(manager *note.Manager) {
+	for _, p := range Plugins() {
+		if p.OnChange != nil {
+			manager.OnChange(p.OnChange)
+		}
+	}
+}
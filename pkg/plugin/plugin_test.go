@@ -0,0 +1,62 @@
+package plugin_test
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/ethanbaker/note/pkg/plugin"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+// Test registering a plugin and attaching its change handler to a manager
+func TestRegister(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	dir := t.TempDir()
+
+	events := []note.Event{}
+	require.Nil(plugin.Register(plugin.Plugin{
+		Name: "test",
+		Exporters: []note.Exporter{{
+			Name:      "test-text",
+			Extension: ".txt",
+			Export:    func(n *note.Note) ([]byte, error) { return []byte(n.Content), nil },
+		}},
+		Commands: []*cobra.Command{{Use: "hello"}},
+		OnChange: func(e note.Event) { events = append(events, e) },
+	}))
+
+	err := plugin.Register(plugin.Plugin{Name: "test"})
+	require.True(errors.Is(err, note.ErrDuplicate))
+	require.NotNil(plugin.Register(plugin.Plugin{}))
+
+	require.Len(plugin.Plugins(), 1)
+	require.Equal("hello", plugin.Commands()[0].Name())
+
+	_, err = note.LookupExporter("test-text")
+	require.Nil(err)
+
+	// The change handler is called for the attached manager
+	manager, err := note.New(
+		note.WithConfigPath(path.Join(dir, "config.json")),
+		note.WithManagerPath(path.Join(dir, "manager.json")),
+		note.WithDirectory(path.Join(dir, "notes")),
+		note.WithCreate(),
+	)
+	require.Nil(err)
+	manager.Config.DefaultAuthor = "Ethan"
+	plugin.Attach(manager)
+
+	require.Nil(manager.CreateNote("note-1"))
+	require.Len(events, 1)
+	require.Equal(note.EventCreated, events[0].Type)
+
+	filepath, err := manager.PublishNoteAs("note-1", dir, "test-text")
+	require.Nil(err)
+	_, err = os.Stat(filepath)
+	require.Nil(err)
+}
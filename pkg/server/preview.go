@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/note"
+)
+
+// How often the previewed note's file is checked for changes
+const previewInterval = 250 * time.Millisecond
+
+// Preview defines a struct that renders a single note as HTML while it is
+// edited. The note's file is watched for writes, and every change is pushed to
+// connected browsers with Server-Sent Events
+type Preview struct {
+	note     *note.Note   // Note being previewed
+	filepath string       // Path of the note's file
+	logger   *slog.Logger // Logger of the note's manager
+
+	mu      sync.Mutex               // Lock guarding the rendered note and clients
+	html    string                   // Most recently rendered version of the note
+	clients map[chan string]struct{} // Channels of connected browsers
+	done    chan struct{}            // Closed when the preview stops watching the note
+}
+
+// Page served to browsers. The rendered note is replaced every time an update
+// event is received
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Filename}} (preview)</title>
+<style>
+body { max-width: 48rem; margin: 2rem auto; padding: 0 1rem; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; }
+pre { padding: 1rem; overflow-x: auto; border-radius: 6px; background: #f6f8fa; }
+table { border-collapse: collapse; }
+th, td { padding: 0.3rem 0.8rem; border: 1px solid #d0d7de; }
+.note-metadata { display: flex; gap: 1rem; color: #656d76; font-size: 0.85rem; }
+#status { position: fixed; top: 0.5rem; right: 0.5rem; color: #656d76; font-size: 0.8rem; }
+</style>
+</head>
+<body>
+<div id="status">live</div>
+<div id="note">{{.HTML}}</div>
+<script>
+const events = new EventSource("events");
+events.onmessage = (event) => { document.getElementById("note").innerHTML = event.data; };
+events.onerror = () => { document.getElementById("status").textContent = "disconnected"; };
+</script>
+</body>
+</html>
+`))
+
+// previewError is an error with its own message that matches a sentinel error
+// of the note package with errors.Is
+type previewError struct {
+	msg string
+	err error
+}
+
+func (e *previewError) Error() string { return e.msg }
+func (e *previewError) Unwrap() error { return e.err }
+
+// Return an error with a formatted message that wraps the provided error
+func wrapf(err error, format string, v ...any) error {
+	return &previewError{msg: fmt.Sprintf(format, v...), err: err}
+}
+
+// NewPreview creates a new preview of the note with the provided filename
+func NewPreview(manager *note.Manager, filename string) (*Preview, error) {
+	filename = strings.ToLower(filename)
+
+	n := manager.GetNote(filename)
+	if n == nil {
+		return nil, wrapf(note.ErrNotFound, "note with name '%s' not found", filename)
+	}
+	if n.Encrypted {
+		return nil, wrapf(note.ErrEncrypted, "note '%s' is encrypted, decrypt it before previewing", filename)
+	}
+
+	p := &Preview{
+		note:     n,
+		filepath: manager.NotePath(filename),
+		logger:   manager.Logger(),
+		clients:  map[chan string]struct{}{},
+		done:     make(chan struct{}),
+	}
+
+	if err := p.render(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Read the note's file and render it as HTML
+func (p *Preview) render() error {
+	content, err := os.ReadFile(p.filepath)
+	if err != nil {
+		return err
+	}
+
+	rendered := *p.note
+	rendered.Content = string(content)
+	rendered.UpdatedAt = time.Now()
+
+	p.mu.Lock()
+	p.html = rendered.AsHTML()
+	p.mu.Unlock()
+
+	return nil
+}
+
+// Watch polls the note's file and pushes a new rendering to every connected
+// browser whenever the file is written. Watch blocks until the provided
+// context is cancelled, after which every connected browser is disconnected
+func (p *Preview) Watch(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(previewInterval)
+	defer ticker.Stop()
+
+	var modTime time.Time
+	var size int64
+	if info, err := os.Stat(p.filepath); err == nil {
+		modTime, size = info.ModTime(), info.Size()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Editors often replace the file when saving, so compare its details
+		// instead of holding it open
+		info, err := os.Stat(p.filepath)
+		if err != nil || (info.ModTime().Equal(modTime) && info.Size() == size) {
+			continue
+		}
+		modTime, size = info.ModTime(), info.Size()
+
+		if err := p.render(); err != nil {
+			p.logger.Error("failed to render preview", "err", err)
+			continue
+		}
+
+		p.logger.Info("note file changed, updating preview")
+
+		p.mu.Lock()
+		for client := range p.clients {
+			select {
+			case client <- p.html:
+			default:
+				// The browser is behind, so it will receive the next update
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+// ServeHTTP serves the preview page at '/' and its event stream at '/events'
+func (p *Preview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/":
+		p.mu.Lock()
+		html := p.html
+		p.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err := previewTemplate.Execute(w, map[string]any{
+			"Filename": p.note.Filename,
+			"HTML":     template.HTML(html),
+		})
+		if err != nil {
+			p.logger.Error("failed to write preview page", "err", err)
+		}
+
+	case "/events":
+		p.serveEvents(w, r)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Stream rendered versions of the note to a browser until it disconnects or
+// the preview stops
+func (p *Preview) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(chan string, 1)
+
+	p.mu.Lock()
+	p.clients[client] = struct{}{}
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.clients, client)
+		p.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-p.done:
+			return
+		case html := <-client:
+			// Every line of the data must be prefixed in the event
+			fmt.Fprintf(w, "data: %s\n\n", strings.ReplaceAll(html, "\n", "\ndata: "))
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/server"
+	"github.com/stretchr/testify/require"
+)
+
+// Test pushing updates of a note to the browser while it is edited
+func TestPreview(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, _ := serverTestSetup(t)
+	require.Nil(manager.CreateNote("note-1"))
+
+	preview, err := server.NewPreview(manager, "note-1")
+	require.Nil(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go preview.Watch(ctx)
+
+	srv := httptest.NewServer(preview)
+	defer srv.Close()
+
+	// The page contains the current rendering of the note
+	response, err := http.Get(srv.URL + "/")
+	require.Nil(err)
+	page := bufio.NewScanner(response.Body)
+	found := false
+	for page.Scan() {
+		found = found || strings.Contains(page.Text(), "<h1>Note 1</h1>")
+	}
+	response.Body.Close()
+	require.True(found)
+
+	// Connect to the event stream
+	response, err = http.Get(srv.URL + "/events")
+	require.Nil(err)
+	defer response.Body.Close()
+	require.Equal("text/event-stream", response.Header.Get("Content-Type"))
+
+	// Write to the note's file as an editor would
+	time.Sleep(50 * time.Millisecond)
+	require.Nil(os.WriteFile(manager.NotePath("note-1"), []byte("# Changed\n\nmore text\n"), 0600))
+
+	events := bufio.NewScanner(response.Body)
+	found = false
+	for events.Scan() {
+		if strings.Contains(events.Text(), "<h1>Changed</h1>") {
+			found = true
+			break
+		}
+	}
+	require.True(found)
+
+	// Stopping the preview closes the event stream
+	cancel()
+	for events.Scan() {
+	}
+}
@@ -0,0 +1,442 @@
+// Package server exposes a note manager over HTTP as a JSON REST API
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethanbaker/note/pkg/note"
+)
+
+// Server defines a struct that serves a note manager over HTTP. Requests are
+// handled one at a time so the manager is never modified concurrently
+type Server struct {
+	manager *note.Manager  // Manager that notes are read from and written to
+	mu      sync.Mutex     // Lock guarding the manager
+	mux     *http.ServeMux // Router for the API endpoints
+	addr    string         // Address the server listens on, whose host requests can name
+}
+
+// noteResponse is the JSON representation of a note returned by the API
+type noteResponse struct {
+	note.Metadata
+	Content string `json:"content"` // Markdown content of the note
+}
+
+// createRequest is the JSON body accepted when creating a note
+type createRequest struct {
+	Filename string  `json:"filename"` // Filename of the new note, made from the title if empty
+	Title    string  `json:"title"`    // Optional title of the new note, in any script
+	Content  *string `json:"content"`  // Optional initial content of the new note
+}
+
+// updateRequest is the JSON body accepted when updating a note
+type updateRequest struct {
+	Content string `json:"content"` // New content of the note
+}
+
+// errorResponse is the JSON body returned when a request fails
+type errorResponse struct {
+	Error string `json:"error"` // Description of the error
+}
+
+// New creates a new server for the provided manager
+func New(manager *note.Manager) *Server {
+	s := &Server{
+		manager: manager,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/api/notes", s.handleNotes)
+	s.mux.HandleFunc("/api/notes/", s.handleNote)
+	s.mux.HandleFunc("/api/search", s.handleSearch)
+
+	return s
+}
+
+// Watch keeps the server's manager in sync with changes made to note files
+// outside of it until the context is done. Scans never run at the same time
+// as requests
+func (s *Server) Watch(ctx context.Context, interval time.Duration) error {
+	return s.manager.Watch(ctx, interval, &s.mu)
+}
+
+// SetAddress sets the address the server listens on. Requests must name its
+// host, localhost, or a loopback IP in their Host header
+func (s *Server) SetAddress(addr string) {
+	s.addr = addr
+}
+
+// ServeHTTP checks the host and origin of the request, authenticates it, and
+// dispatches it to the API
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.manager.Logger().Info("handling request", "method", r.Method, "path", r.URL.Path)
+
+	// Pages that rebind their own hostname to this server can't use the API
+	if !s.allowedHost(r) {
+		s.manager.Logger().Warn("rejected request for unknown host", "host", r.Host)
+		writeError(w, http.StatusForbidden, "host '"+r.Host+"' is not allowed")
+		return
+	}
+
+	// Web pages on other origins can't use the API, even without a token
+	if !sameOrigin(r) {
+		s.manager.Logger().Warn("rejected cross-origin request", "origin", r.Header.Get("Origin"))
+		writeError(w, http.StatusForbidden, "cross-origin requests are not allowed")
+		return
+	}
+
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, "invalid or missing bearer token")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// Return whether the request carries the bearer token required by the
+// manager's config. If no token is configured, every request is authorized
+func (s *Server) authorized(r *http.Request) bool {
+	s.mu.Lock()
+	token := s.manager.Config.APIToken
+	s.mu.Unlock()
+
+	if token == "" {
+		return true
+	}
+
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, "Bearer ")), []byte(token)) == 1
+}
+
+// Return whether the request's Host header names the server's listen address,
+// localhost, or a loopback IP. Any other host may be a hostname that a web
+// page pointed at this server with DNS rebinding, making its requests look
+// like they come from the server's own origin
+func (s *Server) allowedHost(r *http.Request) bool {
+	host := hostname(r.Host)
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+
+	listen := hostname(s.addr)
+	return listen != "" && strings.EqualFold(host, listen)
+}
+
+// Return the hostname of a host that may have a port
+func hostname(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+	return strings.Trim(host, "[]")
+}
+
+// Return whether the request was sent from the server's own origin. Browsers
+// send an Origin header with requests from web pages, while other clients
+// such as scripts and editor plugins usually send none
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return u.Host != "" && strings.EqualFold(u.Host, r.Host)
+}
+
+// Decode the JSON body of a request, writing an error to the response if the
+// body is not JSON. Only 'application/json' bodies are accepted, since web
+// pages can send other types to any origin without a preflight request
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "request body must be application/json")
+		return false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return false
+	}
+
+	return true
+}
+
+// Handle requests to the note collection
+func (s *Server) handleNotes(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		// List the metadata of every note
+		notes := s.manager.GetNotes()
+
+		metadata := make([]note.Metadata, 0, len(notes))
+		for _, n := range notes {
+			metadata = append(metadata, n.Metadata)
+		}
+
+		writeJSON(w, http.StatusOK, metadata)
+
+	case http.MethodPost:
+		// Create a new note
+		body := createRequest{}
+		if !decodeJSON(w, r, &body) {
+			return
+		}
+
+		var err error
+		filename := strings.ToLower(body.Filename)
+		if filename == "" && body.Title != "" {
+			filename, err = s.manager.CreateNoteWithTitle(body.Title)
+		} else {
+			err = s.manager.CreateNoteTitled(filename, body.Title)
+		}
+
+		if errors.Is(err, note.ErrDuplicate) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		} else if errors.Is(err, note.ErrInvalidName) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		} else if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if body.Content != nil {
+			if err := s.manager.UpdateNote(filename, *body.Content); err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+
+		n := s.manager.GetNote(filename)
+		w.Header().Set("Location", "/api/notes/"+n.Filename)
+		writeNote(w, http.StatusCreated, n)
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// Handle requests to a single note at '/api/notes/{filename}',
+// '/api/notes/{filename}/publish', and '/api/notes/{filename}/html'
+func (s *Server) handleNote(w http.ResponseWriter, r *http.Request) {
+	filename := strings.TrimPrefix(r.URL.Path, "/api/notes/")
+
+	// Dispatch publish and render requests
+	if name, ok := strings.CutSuffix(filename, "/publish"); ok {
+		s.handlePublish(w, r, name)
+		return
+	}
+	if name, ok := strings.CutSuffix(filename, "/html"); ok {
+		s.handleHTML(w, r, name)
+		return
+	}
+
+	if filename == "" || strings.Contains(filename, "/") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	n := s.manager.GetNote(filename)
+	if n == nil {
+		writeError(w, http.StatusNotFound, "note with name '"+filename+"' not found")
+		return
+	}
+
+	// The content of encrypted notes is never served, but they can be deleted
+	if n.Encrypted && r.Method != http.MethodDelete {
+		writeError(w, http.StatusForbidden, "note '"+filename+"' is encrypted")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		// Return the note if it changed from the client's copy
+		if match := r.Header.Get("If-None-Match"); match != "" && match == ETag(n) {
+			w.Header().Set("ETag", ETag(n))
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		writeNote(w, http.StatusOK, n)
+
+	case http.MethodPut:
+		// Updates must name the version they are based on so concurrent writes are not lost
+		match := r.Header.Get("If-Match")
+		if match == "" {
+			writeError(w, http.StatusPreconditionRequired, "If-Match header is required")
+			return
+		}
+		if match != "*" && match != ETag(n) {
+			w.Header().Set("ETag", ETag(n))
+			writeError(w, http.StatusPreconditionFailed, "note has been modified")
+			return
+		}
+
+		body := updateRequest{}
+		if !decodeJSON(w, r, &body) {
+			return
+		}
+
+		if err := s.manager.UpdateNote(filename, body.Content); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeNote(w, http.StatusOK, n)
+
+	case http.MethodDelete:
+		// Deletes are checked against the client's version if it is provided
+		if match := r.Header.Get("If-Match"); match != "" && match != "*" && match != ETag(n) {
+			w.Header().Set("ETag", ETag(n))
+			writeError(w, http.StatusPreconditionFailed, "note has been modified")
+			return
+		}
+
+		if err := s.manager.DeleteNote(filename); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// Handle requests to publish a note to the configured publish directory
+func (s *Server) handlePublish(w http.ResponseWriter, r *http.Request, filename string) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	n := s.manager.GetNote(filename)
+	if n == nil {
+		writeError(w, http.StatusNotFound, "note with name '"+filename+"' not found")
+		return
+	}
+	if n.Encrypted {
+		writeError(w, http.StatusForbidden, "note '"+filename+"' is encrypted")
+		return
+	}
+
+	// Requests never choose the directory, so they can't write files anywhere else
+	directory := s.manager.Config.PublishDirectory
+	if directory == "" {
+		writeError(w, http.StatusForbidden, "publishing is disabled ('publish_directory' is not set)")
+		return
+	}
+
+	filepath, err := s.manager.PublishNote(filename, directory)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"path": filepath})
+}
+
+// Handle requests to render a note as HTML
+func (s *Server) handleHTML(w http.ResponseWriter, r *http.Request, filename string) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	n := s.manager.GetNote(filename)
+	if n == nil {
+		writeError(w, http.StatusNotFound, "note with name '"+filename+"' not found")
+		return
+	}
+	if n.Encrypted {
+		writeError(w, http.StatusForbidden, "note '"+filename+"' is encrypted")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("ETag", ETag(n))
+	w.Write([]byte(n.AsHTML()))
+}
+
+// Handle requests to search notes with the 'q' query parameter
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	notes := s.manager.SearchNotes(r.URL.Query().Get("q"))
+
+	metadata := make([]note.Metadata, 0, len(notes))
+	for _, n := range notes {
+		metadata = append(metadata, n.Metadata)
+	}
+
+	writeJSON(w, http.StatusOK, metadata)
+}
+
+// ETag returns the entity tag of a note, which changes every time the note
+// is updated
+func ETag(n *note.Note) string {
+	return `"` + strconv.FormatInt(n.UpdatedAt.UnixNano(), 36) + `"`
+}
+
+// Write a note and its entity tag to the response
+func writeNote(w http.ResponseWriter, status int, n *note.Note) {
+	w.Header().Set("ETag", ETag(n))
+	w.Header().Set("Last-Modified", n.UpdatedAt.UTC().Format(http.TimeFormat))
+
+	writeJSON(w, status, noteResponse{
+		Metadata: n.Metadata,
+		Content:  n.Content,
+	})
+}
+
+// Write an error message to the response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// Write a JSON object to the response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to write response", "err", err)
+	}
+}
@@ -0,0 +1,246 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/note"
+	"github.com/ethanbaker/note/pkg/server"
+	"github.com/stretchr/testify/require"
+)
+
+// Setup before each test by creating a manager in a temporary directory
+func serverTestSetup(t *testing.T) (*note.Manager, http.Handler) {
+	dir := t.TempDir()
+
+	// Modify default paths to test files
+	note.ModifyDefaultDirectoryPath(path.Join(dir, "entries"))
+	note.ModifyConfigPath(path.Join(dir, "config.json"))
+	note.ModifyManagerPath(path.Join(dir, "manager.json"))
+
+	// Get the default manager
+	manager, err := note.GetManager()
+	require.Nil(t, err)
+
+	manager.Config.Editor = "cat"
+	manager.Config.DefaultAuthor = "Ethan"
+
+	return manager, server.New(manager)
+}
+
+// Send a request to the handler and return the recorded response. Requests are
+// sent to localhost, and bodies as JSON, unless the headers set another host or
+// content type
+func request(handler http.Handler, method string, target string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Host = "localhost:8080"
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range headers {
+		if key == "Host" {
+			r.Host = value
+		}
+		r.Header.Set(key, value)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	return w
+}
+
+// Test creating, reading, updating, and deleting a note
+func TestNoteLifecycle(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, handler := serverTestSetup(t)
+
+	// Create a note
+	w := request(handler, http.MethodPost, "/api/notes", `{"filename": "note-1"}`, nil)
+	require.Equal(http.StatusCreated, w.Code)
+	require.NotNil(manager.GetNote("note-1"))
+
+	// Create a duplicate note
+	w = request(handler, http.MethodPost, "/api/notes", `{"filename": "note-1"}`, nil)
+	require.Equal(http.StatusConflict, w.Code)
+
+	// Create a note from a title
+	w = request(handler, http.MethodPost, "/api/notes", `{"title": "Crème Brûlée"}`, nil)
+	require.Equal(http.StatusCreated, w.Code)
+	require.Equal("/api/notes/creme-brulee", w.Header().Get("Location"))
+	require.Equal("Crème Brûlée", manager.GetNote("creme-brulee").Title)
+	require.Nil(manager.DeleteNote("creme-brulee"))
+
+	// Get the note
+	w = request(handler, http.MethodGet, "/api/notes/note-1", "", nil)
+	require.Equal(http.StatusOK, w.Code)
+
+	body := map[string]any{}
+	require.Nil(json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal("note-1", body["filename"])
+	require.Equal("# Note 1\n\n", body["content"])
+
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(etag)
+
+	// List the notes
+	w = request(handler, http.MethodGet, "/api/notes", "", nil)
+	require.Equal(http.StatusOK, w.Code)
+
+	list := []map[string]any{}
+	require.Nil(json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(list, 1)
+
+	// Update the note without a version
+	w = request(handler, http.MethodPut, "/api/notes/note-1", `{"content": "updated"}`, nil)
+	require.Equal(http.StatusPreconditionRequired, w.Code)
+
+	// Update the note with the current version
+	w = request(handler, http.MethodPut, "/api/notes/note-1", `{"content": "updated"}`, map[string]string{"If-Match": etag})
+	require.Equal(http.StatusOK, w.Code)
+	require.Equal("updated", manager.GetNote("note-1").Content)
+	require.NotEqual(etag, w.Header().Get("ETag"))
+
+	// Update the note with a stale version
+	w = request(handler, http.MethodPut, "/api/notes/note-1", `{"content": "lost"}`, map[string]string{"If-Match": etag})
+	require.Equal(http.StatusPreconditionFailed, w.Code)
+	require.Equal("updated", manager.GetNote("note-1").Content)
+
+	// Search for the note
+	w = request(handler, http.MethodGet, "/api/search?q=UPDATED", "", nil)
+	require.Equal(http.StatusOK, w.Code)
+	require.Nil(json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(list, 1)
+
+	// Delete the note
+	w = request(handler, http.MethodDelete, "/api/notes/note-1", "", nil)
+	require.Equal(http.StatusNoContent, w.Code)
+	require.Nil(manager.GetNote("note-1"))
+
+	// Get the deleted note
+	w = request(handler, http.MethodGet, "/api/notes/note-1", "", nil)
+	require.Equal(http.StatusNotFound, w.Code)
+}
+
+// Test publishing a note
+func TestPublish(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, handler := serverTestSetup(t)
+	require.Nil(manager.CreateNote("note-1"))
+
+	// Publishing is disabled until a directory is configured
+	w := request(handler, http.MethodPost, "/api/notes/note-1/publish", "", nil)
+	require.Equal(http.StatusForbidden, w.Code)
+
+	directory := t.TempDir()
+	manager.Config.PublishDirectory = directory
+
+	w = request(handler, http.MethodPost, "/api/notes/note-1/publish", "", nil)
+	require.Equal(http.StatusOK, w.Code)
+	require.Contains(w.Body.String(), path.Join(directory, "note-1.md"))
+}
+
+// Test rejecting requests that web pages on other origins can send
+func TestCrossOrigin(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, handler := serverTestSetup(t)
+
+	// Bodies that aren't JSON are rejected
+	w := request(handler, http.MethodPost, "/api/notes", `{"filename": "note-1"}`, map[string]string{"Content-Type": "text/plain"})
+	require.Equal(http.StatusUnsupportedMediaType, w.Code)
+	require.Nil(manager.GetNote("note-1"))
+
+	// Requests from other origins are rejected
+	w = request(handler, http.MethodPost, "/api/notes", `{"filename": "note-1"}`, map[string]string{"Origin": "https://attacker.example"})
+	require.Equal(http.StatusForbidden, w.Code)
+	require.Nil(manager.GetNote("note-1"))
+
+	w = request(handler, http.MethodGet, "/api/notes", "", map[string]string{"Origin": "null"})
+	require.Equal(http.StatusForbidden, w.Code)
+
+	// Requests from the server's own origin are accepted
+	w = request(handler, http.MethodPost, "/api/notes", `{"filename": "note-1"}`, map[string]string{"Origin": "http://localhost:8080"})
+	require.Equal(http.StatusCreated, w.Code)
+}
+
+// Test rejecting requests for hosts other than the server's, such as after DNS rebinding
+func TestHost(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, handler := serverTestSetup(t)
+
+	headers := map[string]string{"Host": "evil.example", "Origin": "http://evil.example"}
+	w := request(handler, http.MethodPost, "/api/notes", `{"filename": "note-1"}`, headers)
+	require.Equal(http.StatusForbidden, w.Code)
+	require.Nil(manager.GetNote("note-1"))
+
+	w = request(handler, http.MethodGet, "/api/notes", "", map[string]string{"Host": "evil.example:8080"})
+	require.Equal(http.StatusForbidden, w.Code)
+
+	// Loopback IPs and the listen address are accepted
+	for _, host := range []string{"127.0.0.1:8080", "[::1]:8080", "localhost"} {
+		w = request(handler, http.MethodGet, "/api/notes", "", map[string]string{"Host": host})
+		require.Equal(http.StatusOK, w.Code, host)
+	}
+
+	api := server.New(manager)
+	api.SetAddress("notes.lan:8080")
+	w = request(api, http.MethodGet, "/api/notes", "", map[string]string{"Host": "notes.lan:8080"})
+	require.Equal(http.StatusOK, w.Code)
+}
+
+// Test bearer token authentication
+func TestAuthentication(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, handler := serverTestSetup(t)
+	manager.Config.APIToken = "secret"
+
+	w := request(handler, http.MethodGet, "/api/notes", "", nil)
+	require.Equal(http.StatusUnauthorized, w.Code)
+
+	w = request(handler, http.MethodGet, "/api/notes", "", map[string]string{"Authorization": "Bearer wrong"})
+	require.Equal(http.StatusUnauthorized, w.Code)
+
+	w = request(handler, http.MethodGet, "/api/notes", "", map[string]string{"Authorization": "Bearer secret"})
+	require.Equal(http.StatusOK, w.Code)
+}
+
+// Test rendering a note as HTML
+func TestNoteHTML(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, handler := serverTestSetup(t)
+	require.Nil(manager.CreateNote("note-1"))
+
+	w := request(handler, http.MethodGet, "/api/notes/note-1/html", "", nil)
+	require.Equal(http.StatusOK, w.Code)
+	require.Equal("text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Contains(w.Body.String(), "<h1>Note 1</h1>")
+}
+
+// Test that the content of encrypted notes is not served
+func TestEncryptedNote(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, handler := serverTestSetup(t)
+	manager.Passphrase = func() ([]byte, error) { return []byte("correct horse"), nil }
+	require.Nil(manager.CreateNote("secret"))
+	require.Nil(manager.EncryptNote("secret"))
+
+	w := request(handler, http.MethodGet, "/api/notes/secret", "", nil)
+	require.Equal(http.StatusForbidden, w.Code)
+
+	w = request(handler, http.MethodGet, "/api/notes/secret/html", "", nil)
+	require.Equal(http.StatusForbidden, w.Code)
+
+	w = request(handler, http.MethodDelete, "/api/notes/secret", "", nil)
+	require.Equal(http.StatusNoContent, w.Code)
+}
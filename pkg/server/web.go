@@ -0,0 +1,36 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/ethanbaker/note/pkg/note"
+)
+
+// Static files of the browser-based note viewer and editor
+//
+//go:embed web
+var webFiles embed.FS
+
+// NewWeb creates a handler that serves the browser-based note viewer and
+// editor alongside the JSON API it is built on. The static files are public,
+// while the API keeps requiring the configured bearer token
+func NewWeb(manager *note.Manager) http.Handler {
+	return WebHandler(New(manager))
+}
+
+// WebHandler serves the browser-based note viewer and editor alongside an
+// existing API server
+func WebHandler(api *Server) http.Handler {
+	static, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", api)
+	mux.Handle("/", http.FileServer(http.FS(static)))
+
+	return mux
+}
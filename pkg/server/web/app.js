@@ -0,0 +1,197 @@
+// Browser-based note viewer and editor built on the note JSON API
+"use strict";
+
+const state = {
+  current: null, // Filename of the open note
+  etag: null, // Entity tag of the open note, sent back when saving
+};
+
+const $ = (id) => document.getElementById(id);
+
+// Send a request to the API, asking for a bearer token if one is required
+async function api(method, path, body, headers = {}) {
+  const token = localStorage.getItem("note-token");
+  if (token) {
+    headers["Authorization"] = "Bearer " + token;
+  }
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+    body = JSON.stringify(body);
+  }
+
+  const response = await fetch(path, { method, headers, body });
+
+  if (response.status === 401) {
+    const entered = prompt("API token");
+    if (entered === null) {
+      throw new Error("authentication required");
+    }
+    localStorage.setItem("note-token", entered);
+    return api(method, path, body === undefined ? undefined : JSON.parse(body), headers);
+  }
+
+  return response;
+}
+
+// Return the error message of a failed response
+async function errorMessage(response) {
+  try {
+    return (await response.json()).error;
+  } catch {
+    return response.statusText;
+  }
+}
+
+// Show exactly one of the main sections
+function show(section) {
+  for (const id of ["empty", "viewer", "editor"]) {
+    $(id).hidden = id !== section;
+  }
+}
+
+// Load the list of notes, filtered by the search box
+async function loadNotes() {
+  const query = $("search").value.trim();
+  const response = query
+    ? await api("GET", "/api/search?q=" + encodeURIComponent(query))
+    : await api("GET", "/api/notes");
+  const notes = await response.json();
+
+  notes.sort((a, b) => new Date(b.updatedAt) - new Date(a.updatedAt));
+
+  const list = $("notes");
+  list.replaceChildren();
+
+  for (const note of notes) {
+    const item = document.createElement("li");
+    item.textContent = note.filename;
+    item.classList.toggle("active", note.filename === state.current);
+
+    const updated = document.createElement("small");
+    updated.textContent = new Date(note.updatedAt).toLocaleString();
+    item.appendChild(updated);
+
+    item.addEventListener("click", () => openNote(note.filename));
+    list.appendChild(item);
+  }
+}
+
+// Render a note in the viewer
+async function openNote(filename) {
+  const response = await api("GET", "/api/notes/" + encodeURIComponent(filename) + "/html");
+  if (!response.ok) {
+    alert(await errorMessage(response));
+    return;
+  }
+
+  state.current = filename;
+  $("viewer-title").textContent = filename;
+  $("rendered").innerHTML = await response.text();
+  location.hash = filename;
+
+  show("viewer");
+  loadNotes();
+}
+
+// Open the current note in the editor
+async function editNote() {
+  const response = await api("GET", "/api/notes/" + encodeURIComponent(state.current));
+  if (!response.ok) {
+    alert(await errorMessage(response));
+    return;
+  }
+
+  const note = await response.json();
+  state.etag = response.headers.get("ETag");
+
+  $("editor-title").textContent = note.filename;
+  $("content").value = note.content;
+  $("status").textContent = "";
+
+  show("editor");
+  $("content").focus();
+}
+
+// Save the editor's content, refusing to overwrite changes made elsewhere
+async function saveNote() {
+  const response = await api(
+    "PUT",
+    "/api/notes/" + encodeURIComponent(state.current),
+    { content: $("content").value },
+    { "If-Match": state.etag },
+  );
+
+  if (response.status === 412) {
+    $("status").textContent = "The note was changed elsewhere. Copy your changes and reopen the note.";
+    return;
+  }
+  if (!response.ok) {
+    $("status").textContent = await errorMessage(response);
+    return;
+  }
+
+  state.etag = response.headers.get("ETag");
+  $("status").textContent = "Saved";
+  loadNotes();
+}
+
+// Create a new note and open it in the editor
+async function newNote() {
+  const filename = prompt("Note name (a-z, 0-9, '-', '_')");
+  if (!filename) {
+    return;
+  }
+
+  const response = await api("POST", "/api/notes", { filename });
+  if (!response.ok) {
+    alert(await errorMessage(response));
+    return;
+  }
+
+  state.current = (await response.json()).filename;
+  await loadNotes();
+  await editNote();
+}
+
+// Delete the current note
+async function deleteNote() {
+  if (!confirm(`Delete "${state.current}"?`)) {
+    return;
+  }
+
+  const response = await api("DELETE", "/api/notes/" + encodeURIComponent(state.current));
+  if (!response.ok) {
+    alert(await errorMessage(response));
+    return;
+  }
+
+  state.current = null;
+  location.hash = "";
+  show("empty");
+  loadNotes();
+}
+
+let searchTimer = null;
+$("search").addEventListener("input", () => {
+  clearTimeout(searchTimer);
+  searchTimer = setTimeout(loadNotes, 200);
+});
+
+$("new-note").addEventListener("click", newNote);
+$("edit").addEventListener("click", editNote);
+$("delete").addEventListener("click", deleteNote);
+$("save").addEventListener("click", saveNote);
+$("cancel").addEventListener("click", () => openNote(state.current));
+
+// Save with Ctrl+S or Cmd+S while editing
+document.addEventListener("keydown", (event) => {
+  if ((event.ctrlKey || event.metaKey) && event.key === "s" && !$("editor").hidden) {
+    event.preventDefault();
+    saveNote();
+  }
+});
+
+loadNotes();
+if (location.hash.length > 1) {
+  openNote(decodeURIComponent(location.hash.slice(1)));
+}
@@ -0,0 +1,30 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethanbaker/note/pkg/server"
+	"github.com/stretchr/testify/require"
+)
+
+// Test serving the web app and its API
+func TestWeb(t *testing.T) {
+	// Setup test
+	require := require.New(t)
+	manager, _ := serverTestSetup(t)
+	manager.Config.APIToken = "secret"
+	handler := server.NewWeb(manager)
+
+	// Static files do not require authentication
+	w := request(handler, http.MethodGet, "/", "", nil)
+	require.Equal(http.StatusOK, w.Code)
+	require.Contains(w.Body.String(), "<title>Notes</title>")
+
+	w = request(handler, http.MethodGet, "/app.js", "", nil)
+	require.Equal(http.StatusOK, w.Code)
+
+	// The API still requires authentication
+	w = request(handler, http.MethodGet, "/api/notes", "", nil)
+	require.Equal(http.StatusUnauthorized, w.Code)
+}